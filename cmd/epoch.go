@@ -0,0 +1,83 @@
+package main
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/repository"
+	"auth-service/internal/types/queries"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const epochUsage = `usage: auth-service epoch <subcommand>
+
+subcommands:
+  show [-user GUID]    print the not-before epoch in effect, for the user if given
+  bump [-user GUID]    reject every token issued until now, of the user if given or of everyone
+
+Replicas pick a bumped epoch up within session.revocation_cache_ttl.`
+
+var errEpochUsage = errors.New(epochUsage)
+
+func runEpoch(cfg *config.Config, args []string) error {
+	if len(args) == 0 || (args[0] != "show" && args[0] != "bump") {
+		return errEpochUsage
+	}
+
+	flags := flag.NewFlagSet("epoch "+args[0], flag.ContinueOnError)
+	user := flags.String("user", "", "user GUID, the global epoch when omitted")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *user != "" {
+		if err := uuid.Validate(*user); err != nil {
+			return fmt.Errorf("invalid user guid: %w", err)
+		}
+	}
+
+	ctx := context.Background()
+
+	repo, err := repository.OpenPostgresRepo(ctx, cfg.DBConn)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	switch args[0] {
+	case "show":
+		notBefore, err := repo.GetNotBefore(ctx, *user)
+		if err != nil {
+			return err
+		}
+		if notBefore.IsZero() {
+			fmt.Println("no epoch set")
+			return nil
+		}
+		fmt.Printf("not before %s\n", notBefore.Format(time.RFC3339))
+	case "bump":
+		notBefore, err := repo.BumpNotBefore(ctx, &queries.BumpNotBeforeQuery{
+			UserGUID:  *user,
+			NotBefore: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("bumped %s epoch to %s\n", epochScope(*user), notBefore.Format(time.RFC3339))
+	default:
+		return errEpochUsage
+	}
+
+	return nil
+}
+
+func epochScope(userGUID string) string {
+	if userGUID == "" {
+		return "global"
+	}
+	return "user " + userGUID
+}
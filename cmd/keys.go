@@ -0,0 +1,118 @@
+package main
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/keyring"
+	"auth-service/internal/repository"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const keysUsage = `usage: auth-service keys <subcommand>
+
+subcommands:
+  list                          show stored signing keys
+  add [-alg ALG] [-file PEM]    store a verification-only key, generated unless a PEM file is given
+  promote <kid>                 make the key the signing one, the previous key keeps verifying
+                                for server.access_token_expire
+  retire <kid>                  remove an inactive key, tokens signed with it stop verifying
+
+Keys are published to every replica on the next ring reload, so wait at least
+keyring.reload_interval between "add" and "promote". Key material is encrypted
+at rest with KEY_ENCRYPTION_KEY when it is set.`
+
+var errKeysUsage = errors.New(keysUsage)
+
+func runKeys(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errKeysUsage
+	}
+
+	ctx := context.Background()
+
+	repo, err := repository.OpenPostgresRepo(ctx, cfg.DBConn)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	switch args[0] {
+	case "list":
+		return listKeys(ctx, repo)
+	case "add":
+		flags := flag.NewFlagSet("keys add", flag.ContinueOnError)
+		alg := flags.String("alg", cfg.KeyRing.RotationAlgorithm, "signing algorithm: HS512, RS256, ES256 or EdDSA")
+		file := flags.String("file", "", "PEM private key to import instead of generating one")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		var material []byte
+		if *file != "" {
+			material, err = os.ReadFile(*file)
+			if err != nil {
+				return err
+			}
+		}
+
+		sealer, err := keyring.NewSealer(cfg.KeyRing.EncryptionKey)
+		if err != nil {
+			return err
+		}
+
+		key, err := keyring.AddKey(ctx, repo, sealer, *alg, material)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("added %s key %s\n", key.Algorithm(), key.ID())
+	case "promote":
+		if len(args) != 2 {
+			return errKeysUsage
+		}
+		if err := keyring.PromoteKey(ctx, repo, args[1], cfg.KeyRetention()); err != nil {
+			return err
+		}
+		fmt.Printf("promoted key %s\n", args[1])
+	case "retire":
+		if len(args) != 2 {
+			return errKeysUsage
+		}
+		if err := keyring.RetireKey(ctx, repo, args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("retired key %s\n", args[1])
+	default:
+		return errKeysUsage
+	}
+
+	return nil
+}
+
+func listKeys(ctx context.Context, repo repository.Repository) error {
+	keys, err := repo.ListSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KID\tALG\tSTATE\tCREATED")
+	for _, key := range keys {
+		state := "pending"
+		switch {
+		case key.Active:
+			state = "active"
+		case !key.VerifyUntil.IsZero():
+			state = "verify until " + key.VerifyUntil.Format(time.RFC3339)
+		case !key.ActivatedAt.IsZero():
+			state = "verify"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", key.ID, key.Algorithm, state, key.CreatedAt.Format(time.RFC3339))
+	}
+
+	return w.Flush()
+}
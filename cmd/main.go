@@ -3,6 +3,7 @@ package main
 import (
 	"auth-service/internal/app"
 	"auth-service/internal/config"
+	"context"
 	"errors"
 	"fmt"
 	"log"
@@ -14,6 +15,14 @@ import (
 	"syscall"
 )
 
+const usage = `usage: auth-service [command]
+
+commands:
+  serve     run the service (default)
+  keys      manage signing keys, see "auth-service keys help"
+  epoch     manage not-before epochs, see "auth-service epoch help"
+  migrate   manage the database schema, see "auth-service migrate help"`
+
 func main() {
 	env := os.Getenv("ENV")
 	if env == "" {
@@ -26,6 +35,26 @@ func main() {
 	cfg, err := config.New(configPath)
 	reportOnError(err)
 
+	command, args := "serve", []string{}
+	if len(os.Args) > 1 {
+		command, args = os.Args[1], os.Args[2:]
+	}
+
+	switch command {
+	case "serve":
+		serve(cfg)
+	case "keys":
+		reportOnError(runKeys(cfg, args))
+	case "epoch":
+		reportOnError(runEpoch(cfg, args))
+	case "migrate":
+		reportOnError(runMigrate(cfg, args))
+	default:
+		log.Fatalln(usage)
+	}
+}
+
+func serve(cfg *config.Config) {
 	app, err := app.New(cfg)
 	reportOnError(err)
 
@@ -42,7 +71,11 @@ func main() {
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
 
 	<-ch
-	if err := app.Shutdown(); err != nil {
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := app.Shutdown(ctx); err != nil {
 		log.Fatalf("app shutdown failed: %v", err)
 	}
 	slog.Info("app shutdown completed")
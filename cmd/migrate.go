@@ -0,0 +1,89 @@
+package main
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/repository"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const migrateUsage = `usage: auth-service migrate <subcommand>
+
+subcommands:
+  up                 apply every pending migration
+  down [N]           roll the last N migrations back, 1 by default
+  to <version>       migrate up or down to the version
+  status             print the applied and the latest migration versions
+  force <version>    mark the version applied without running it, after fixing a failed migration by hand
+
+Migrations are embedded in the binary. The service migrates up on start
+unless db-conn.skip_migrations (DB_SKIP_MIGRATIONS) is set; the keys and epoch
+commands never migrate and refuse to run until the schema is up to date.`
+
+var errMigrateUsage = errors.New(migrateUsage)
+
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	var version int
+	steps := 1
+	switch {
+	case (args[0] == "up" || args[0] == "status") && len(args) == 1:
+	case args[0] == "down" && len(args) <= 2:
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return errMigrateUsage
+			}
+			steps = n
+		}
+	case (args[0] == "to" || args[0] == "force") && len(args) == 2:
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 || (args[0] == "to" && n == 0) {
+			return errMigrateUsage
+		}
+		version = n
+	default:
+		return errMigrateUsage
+	}
+
+	migrator, err := repository.NewPostgresMigrator(cfg.DBConn)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch args[0] {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		err = migrator.Down(steps)
+	case "to":
+		err = migrator.To(uint(version))
+	case "force":
+		err = migrator.Force(version)
+	}
+	if err != nil {
+		return err
+	}
+
+	return printMigrationStatus(migrator)
+}
+
+func printMigrationStatus(migrator repository.Migrator) error {
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	dirty := ""
+	if status.Dirty {
+		dirty = " (dirty, fix the schema and force a version)"
+	}
+	fmt.Printf("schema at version %d%s, latest migration %d\n", status.Current, dirty, status.Expected)
+
+	return nil
+}
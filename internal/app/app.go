@@ -1,25 +1,43 @@
 package app
 
 import (
+	"auth-service/internal/clientip"
 	"auth-service/internal/config"
 	"auth-service/internal/controller"
+	"auth-service/internal/health"
+	"auth-service/internal/keyring"
+	"auth-service/internal/metrics"
 	"auth-service/internal/middleware"
+	"auth-service/internal/notifier"
 	"auth-service/internal/repository"
+	"auth-service/internal/revocation"
 	"auth-service/internal/service"
+	"auth-service/internal/sweeper"
 	"auth-service/internal/tokenizer"
+	"auth-service/internal/tracing"
 	"auth-service/pkg/cryptor"
 	"auth-service/pkg/logger"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"time"
 )
 
 const AppName = "Auth-Service"
 
 type App struct {
-	Server *http.Server
+	Server          *http.Server
+	metricsServer   *http.Server
+	repo            repository.Repository
+	cryptor         cryptor.Cryptor
+	notifier        notifier.Dispatcher
+	health          health.Checker
+	background      *backgroundJobs
+	shutdownDelay   time.Duration
+	shutdownTracing func(context.Context) error
 }
 
 func New(cfg *config.Config) (*App, error) {
@@ -28,33 +46,139 @@ func New(cfg *config.Config) (*App, error) {
 		return nil, err
 	}
 
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing, AppName)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := metrics.New()
+
 	repo, err := repository.NewPostgresRepo(cfg.DBConn)
 	if err != nil {
 		return nil, err
 	}
+	repo = repository.NewInstrumented(repo, metrics)
+
+	ipResolver, err := clientip.New(cfg.TrustedProxies, cfg.ClientIPHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshCryptor := cryptor.New(cfg.AsyncHashingLimit, cfg.AsyncHashingQueue, cfg.AsyncHashingWait)
+	if cfg.RefreshTokenHashing == config.HashingHMACSHA256 {
+		refreshCryptor = cryptor.NewHMAC([]byte(cfg.RefreshTokenPepper), refreshCryptor)
+	}
+	registerGauges(metrics, repo, refreshCryptor)
+
+	var staticKey *tokenizer.Key
+	if cfg.HasStaticKey() {
+		staticKey, err = tokenizer.LoadKey(cfg.AccessTokenAlgorithm, cfg.AccessTokenKeyID, cfg.AccessTokenSecretKey, cfg.AccessTokenKeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sealer, err := keyring.NewSealer(cfg.KeyRing.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keyRing, err := keyring.New(context.Background(), cfg.KeyRing, repo, sealer, staticKey, cfg.KeyRetention(), logger)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenizer := tokenizer.New(AppName, keyRing, cfg.AccessTokenExpire, cfg.RefreshTokenExpire, cfg.RefreshCookie)
 
-	cryptor := cryptor.New(cfg.AsyncHashingLimit)
-	tokenizer := tokenizer.New(AppName, cfg.AccessTokenSecretKey, cfg.AccessTokenExpire, cfg.RefreshTokenExpire)
+	notifier := notifier.NewQueue(notifier.New(cfg.Notifier), cfg.Notifier.QueueSize, metrics, logger)
 
-	service := service.New(repo, cryptor, tokenizer, logger)
+	revocationChecker := revocation.New(repo, cfg.RevocationCheck, cfg.RevocationCacheTTL)
 
-	controller := controller.New(service, logger)
-	middleware := middleware.New(tokenizer)
+	service := service.NewInstrumented(service.New(cfg.Session, cfg.OAuth, revocation.NewEvicting(repo, revocationChecker),
+		refreshCryptor, tokenizer, notifier, logger), metrics)
 
-	return &App{
+	clientSecrets, err := cfg.ClientSecrets()
+	if err != nil {
+		return nil, err
+	}
+
+	middleware := middleware.New(tokenizer, revocationChecker, cfg.CSRF, cfg.CORS, cfg.Admin, clientSecrets)
+	health := health.New(repo, keyRing, refreshCryptor, logger)
+	controller := controller.New(service, tokenizer, middleware, ipResolver, health, logger)
+
+	app := &App{
 		Server: &http.Server{
 			Addr:    fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
-			Handler: initRoutes(controller, middleware),
+			Handler: initRoutes(controller, middleware, metrics, cfg.Metrics),
 		},
-	}, nil
+		repo:            repo,
+		cryptor:         refreshCryptor,
+		notifier:        notifier,
+		health:          health,
+		background:      newBackgroundJobs(),
+		shutdownDelay:   cfg.ShutdownDelay,
+		shutdownTracing: shutdownTracing,
+	}
+
+	app.background.Go(keyRing.Run)
+	app.background.Go(sweeper.New(repo, cfg.SweepInterval, cfg.SweepBatchSize, metrics, logger).Run)
+
+	if cfg.Metrics.Enabled && !cfg.Metrics.OnAPIListener {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle(cfg.Metrics.Path, metrics.Handler())
+		app.metricsServer = &http.Server{
+			Addr:    cfg.Metrics.Address,
+			Handler: metricsMux,
+		}
+	}
+
+	return app, nil
 }
 
 func (s *App) Run() error {
+	if s.metricsServer != nil {
+		go func() {
+			slog.Info("metrics starting on", slog.String("address", s.metricsServer.Addr))
+			err := s.metricsServer.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("metrics server failed", slog.String("error", err.Error()))
+			}
+		}()
+	}
+
 	slog.Info("app starting on", slog.String("address", s.Server.Addr))
 	return s.Server.ListenAndServe()
 }
 
-func (s *App) Shutdown() error {
+// Shutdown stops the app in order, each step running even if an earlier one failed or ctx expired:
+// it reports unready and keeps serving for the shutdown delay, stops accepting connections
+// and drains in-flight requests, stops background jobs, delivers queued security events,
+// drains the hashing pool, closes the database pool and finally flushes telemetry.
+func (s *App) Shutdown(ctx context.Context) error {
 	slog.Info("app shutting down...")
-	return s.Server.Shutdown(context.Background())
+	s.health.MarkShuttingDown()
+
+	delay := time.NewTimer(s.shutdownDelay)
+	select {
+	case <-delay.C:
+	case <-ctx.Done():
+		delay.Stop()
+	}
+
+	err := s.Server.Shutdown(ctx)
+	if err != nil {
+		err = errors.Join(err, s.Server.Close())
+	}
+
+	if s.metricsServer != nil {
+		err = errors.Join(err, s.metricsServer.Shutdown(ctx))
+	}
+
+	err = errors.Join(err, s.background.Stop(ctx))
+	err = errors.Join(err, s.notifier.Close(ctx))
+	err = errors.Join(err, s.cryptor.Close(ctx))
+	err = errors.Join(err, s.repo.Close())
+	err = errors.Join(err, s.shutdownTracing(ctx))
+
+	return err
 }
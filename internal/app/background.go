@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"sync"
+)
+
+// backgroundJobs runs the long-lived jobs of the app until they are stopped.
+type backgroundJobs struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newBackgroundJobs() *backgroundJobs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &backgroundJobs{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Go runs the job until its context is cancelled.
+func (b *backgroundJobs) Go(job func(ctx context.Context)) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		job(b.ctx)
+	}()
+}
+
+// Stop cancels the jobs and waits for them to return, at most until ctx is done.
+func (b *backgroundJobs) Stop(ctx context.Context) error {
+	b.cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"auth-service/internal/metrics"
+	"auth-service/internal/repository"
+	"auth-service/pkg/cryptor"
+	"context"
+	"time"
+)
+
+const activeSessionsTimeout = 2 * time.Second
+
+// registerGauges samples the hashing pool load and the active session count on every scrape.
+func registerGauges(m *metrics.Metrics, repo repository.Repository, refreshCryptor cryptor.Cryptor) {
+	m.GaugeFunc("cryptor", "pool_workers", "Hashing pool workers.", func() float64 {
+		return float64(refreshCryptor.Stats().Workers)
+	})
+	m.GaugeFunc("cryptor", "pool_busy_workers", "Hashing pool workers running a job.", func() float64 {
+		return float64(refreshCryptor.Stats().Busy)
+	})
+	m.GaugeFunc("cryptor", "pool_queue_length", "Hashing jobs waiting for a worker.", func() float64 {
+		return float64(refreshCryptor.Stats().Queued)
+	})
+	m.GaugeFunc("cryptor", "pool_queue_capacity", "Hashing job queue capacity.", func() float64 {
+		return float64(refreshCryptor.Stats().QueueCapacity)
+	})
+
+	m.GaugeFunc("sessions", "active", "Sessions not expired yet.", func() float64 {
+		ctx, cancel := context.WithTimeout(context.Background(), activeSessionsTimeout)
+		defer cancel()
+
+		count, err := repo.CountActiveSessions(ctx)
+		if err != nil {
+			return -1
+		}
+		return float64(count)
+	})
+}
@@ -1,24 +1,52 @@
 package app
 
 import (
+	"auth-service/internal/config"
 	"auth-service/internal/controller"
+	"auth-service/internal/metrics"
 	"auth-service/internal/middleware"
+	"auth-service/internal/tracing"
 	"net/http"
 
 	"github.com/gorilla/mux"
 )
 
-func initRoutes(controller controller.Controller, mw middleware.Middleware) *mux.Router {
-	router := mux.NewRouter().PathPrefix("/api/auth").Subrouter()
+func initRoutes(controller controller.Controller, mw middleware.Middleware, m *metrics.Metrics, metricsCfg config.Metrics) http.Handler {
+	root := mux.NewRouter()
+	root.Use(tracing.InstrumentHTTP(), m.InstrumentHTTP())
+
+	root.HandleFunc("/healthz", controller.HandleHealthz()).Methods(http.MethodGet)
+	root.HandleFunc("/readyz", controller.HandleReadyz()).Methods(http.MethodGet)
+
+	if metricsCfg.Enabled && metricsCfg.OnAPIListener {
+		root.Handle(metricsCfg.Path, m.Handler()).Methods(http.MethodGet)
+	}
+
+	router := root.PathPrefix("/api/auth").Subrouter()
 
 	router.HandleFunc("/login", controller.HandleLogin()).Methods(http.MethodPost)
-	router.HandleFunc("/refresh", controller.HandleRefresh()).Methods(http.MethodPost)
+	router.Handle("/refresh", mw.CSRF()(controller.HandleRefresh())).Methods(http.MethodPost)
+	router.HandleFunc("/.well-known/jwks.json", controller.HandleJWKS()).Methods(http.MethodGet)
 
 	protected := router.NewRoute().Subrouter()
 	protected.Use(mw.Auth())
 
 	protected.HandleFunc("/current", controller.HandleGetCurrentUser()).Methods(http.MethodGet)
-	protected.HandleFunc("/logout", controller.HandleLogout()).Methods(http.MethodPost)
+	protected.Handle("/logout", mw.CSRF()(controller.HandleLogout())).Methods(http.MethodPost)
+	protected.HandleFunc("/sessions", controller.HandleListSessions()).Methods(http.MethodGet)
+	protected.HandleFunc("/sessions/revoke-others", controller.HandleRevokeOtherSessions()).Methods(http.MethodPost)
+	protected.HandleFunc("/sessions/{session_id}", controller.HandleRevokeSession()).Methods(http.MethodDelete)
+
+	clients := router.NewRoute().Subrouter()
+	clients.Use(mw.Client())
+
+	clients.HandleFunc("/introspect", controller.HandleIntrospect()).Methods(http.MethodPost)
+	clients.HandleFunc("/revoke", controller.HandleRevoke()).Methods(http.MethodPost)
+
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(mw.Admin())
+
+	admin.HandleFunc("/not-before", controller.HandleBumpNotBefore()).Methods(http.MethodPost)
 
-	return router
+	return mw.CORS()(root)
 }
@@ -0,0 +1,197 @@
+package clientip
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Supported client IP headers.
+const (
+	ForwardedHeader     = "Forwarded"
+	XForwardedForHeader = "X-Forwarded-For"
+	XRealIPHeader       = "X-Real-IP"
+)
+
+// Resolver determines the address of the client a request originates from.
+type Resolver interface {
+	ClientIP(r *http.Request) netip.Addr
+}
+
+type resolver struct {
+	trustedProxies []netip.Prefix
+	headers        []string
+}
+
+// New creates a resolver honouring the given headers, in order of precedence,
+// only for requests whose direct peer lies within one of the trusted proxy networks.
+// Trusted proxies are CIDRs or single addresses.
+func New(trustedProxies, headers []string) (Resolver, error) {
+	prefixes := make([]netip.Prefix, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		prefix, err := parseTrustedProxy(strings.TrimSpace(proxy))
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrTrustedProxyInvalid, proxy, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	canonical := make([]string, 0, len(headers))
+	for _, header := range headers {
+		header = http.CanonicalHeaderKey(strings.TrimSpace(header))
+		switch header {
+		case http.CanonicalHeaderKey(ForwardedHeader),
+			http.CanonicalHeaderKey(XForwardedForHeader),
+			http.CanonicalHeaderKey(XRealIPHeader):
+			canonical = append(canonical, header)
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrHeaderUnsupported, header)
+		}
+	}
+
+	return &resolver{
+		trustedProxies: prefixes,
+		headers:        canonical,
+	}, nil
+}
+
+// ClientIP returns the normalised client address, or the zero netip.Addr when none can be parsed.
+func (res *resolver) ClientIP(r *http.Request) netip.Addr {
+	var peer netip.Addr
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err == nil {
+		peer = normalize(addrPort.Addr())
+	}
+
+	if !peer.IsValid() || !res.isTrusted(peer) {
+		return peer
+	}
+
+	for _, header := range res.headers {
+		values := r.Header.Values(header)
+		if len(values) == 0 {
+			continue
+		}
+
+		var hops []netip.Addr
+		switch header {
+		case http.CanonicalHeaderKey(ForwardedHeader):
+			hops = forwardedHops(values)
+		case http.CanonicalHeaderKey(XForwardedForHeader):
+			hops = xForwardedForHops(values)
+		case http.CanonicalHeaderKey(XRealIPHeader):
+			hops = []netip.Addr{parseHop(values[0])}
+		}
+
+		return res.walk(peer, hops)
+	}
+
+	return peer
+}
+
+// walk goes through the proxy chain from right to left and returns
+// the first address not belonging to a trusted proxy.
+// An unparsable hop stops the walk at the last trusted address seen.
+func (res *resolver) walk(peer netip.Addr, hops []netip.Addr) netip.Addr {
+	last := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := hops[i]
+		if !hop.IsValid() {
+			return last
+		}
+		if !res.isTrusted(hop) {
+			return hop
+		}
+		last = hop
+	}
+
+	return last
+}
+
+func (res *resolver) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range res.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseTrustedProxy(value string) (netip.Prefix, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = normalize(addr)
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func xForwardedForHops(values []string) []netip.Addr {
+	var hops []netip.Addr
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, parseHop(hop))
+		}
+	}
+
+	return hops
+}
+
+// forwardedHops extracts the "for" parameter of every RFC 7239 forwarded-element.
+// Elements without one, obfuscated identifiers and "unknown" yield an invalid hop.
+func forwardedHops(values []string) []netip.Addr {
+	var hops []netip.Addr
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			var hop netip.Addr
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(key, "for") {
+					hop = parseHop(strings.Trim(val, `"`))
+					break
+				}
+			}
+			hops = append(hops, hop)
+		}
+	}
+
+	return hops
+}
+
+// parseHop accepts a bare address, an address with a port and a bracketed IPv6 address.
+func parseHop(value string) netip.Addr {
+	value = strings.TrimSpace(value)
+
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return normalize(addr)
+	}
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return normalize(addrPort.Addr())
+	}
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		if addr, err := netip.ParseAddr(value[1 : len(value)-1]); err == nil {
+			return normalize(addr)
+		}
+	}
+
+	return netip.Addr{}
+}
+
+// normalize unmaps IPv4-mapped IPv6 addresses and strips zones, so one host always has one representation.
+func normalize(addr netip.Addr) netip.Addr {
+	return addr.Unmap().WithZone("")
+}
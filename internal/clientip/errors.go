@@ -0,0 +1,9 @@
+package clientip
+
+import "errors"
+
+// Resolver configuration errors.
+var (
+	ErrTrustedProxyInvalid = errors.New("clientip: invalid trusted proxy")
+	ErrHeaderUnsupported   = errors.New("clientip: unsupported client ip header")
+)
@@ -1,29 +1,222 @@
 package config
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+// Refresh token hashing schemes.
+const (
+	// HashingBcrypt bcrypts the SHA-256 digest of the token, which exceeds the 72 bytes bcrypt accepts.
+	HashingBcrypt = "bcrypt"
+	// HashingHMACSHA256 hashes with a keyed HMAC, still verifying bcrypt hashes until they are rewritten on refresh.
+	HashingHMACSHA256 = "hmac-sha256"
+)
+
+const (
+	minPepperLength     = 32
+	minCSRFSecretLength = 32
+	minAdminTokenLength = 32
+)
+
+// SameSite modes of the refresh cookie.
+const (
+	SameSiteLax    = "lax"
+	SameSiteStrict = "strict"
+	SameSiteNone   = "none"
+)
+
+// Tracing exporters.
+const (
+	TracingExporterNone   = "none"
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+// HostCookiePrefix is prepended to the cookie name to lock it to the host, see RFC 6265bis.
+const HostCookiePrefix = "__Host-"
+
+// IP change policies applied when a session is refreshed from a new IP address.
+const (
+	// IPChangePolicyNotifyAllow warns and adopts the new IP for the session,
+	// so later refreshes from that IP do not warn again.
+	IPChangePolicyNotifyAllow = "notify_allow"
+	// IPChangePolicyReject warns, deletes the session and refuses the refresh.
+	IPChangePolicyReject = "reject"
+)
+
 type Config struct {
-	Server `yaml:"server"`
-	DBConn `yaml:"db-conn"`
+	Server   `yaml:"server"`
+	Session  `yaml:"session"`
+	Notifier `yaml:"notifier"`
+	CSRF     `yaml:"csrf"`
+	CORS     `yaml:"cors"`
+	Admin    `yaml:"admin"`
+	OAuth    `yaml:"oauth"`
+	Metrics  `yaml:"metrics"`
+	Tracing  `yaml:"tracing"`
+	KeyRing  `yaml:"keyring"`
+	DBConn   `yaml:"db-conn"`
 }
 
 type Server struct {
 	Host                 string        `yaml:"host" env:"HOST"`
 	Port                 string        `yaml:"port" env:"PORT"`
-	AccessTokenSecretKey string        `env:"ACCESS_TOKEN_SECRET" env-required:"true"`
+	AccessTokenAlgorithm string        `yaml:"access_token_algorithm" env:"ACCESS_TOKEN_ALGORITHM" env-default:"HS512"`
+	AccessTokenSecretKey string        `env:"ACCESS_TOKEN_SECRET"`
+	AccessTokenKeyFile   string        `yaml:"access_token_key_file" env:"ACCESS_TOKEN_KEY_FILE"`
+	AccessTokenKeyID     string        `yaml:"access_token_key_id" env:"ACCESS_TOKEN_KEY_ID"`
 	AccessTokenExpire    time.Duration `yaml:"access_token_expire" env-default:"12h"`
 	RefreshTokenExpire   time.Duration `yaml:"refresh_token_expire" env-default:"48h"`
 	LogLevel             string        `yaml:"log_level" env-default:"info"`
 	AsyncHashingLimit    int           `yaml:"async_hashing_limit" env-default:"10"`
+	AsyncHashingQueue    int           `yaml:"async_hashing_queue" env-default:"100"`
+	AsyncHashingWait     time.Duration `yaml:"async_hashing_wait" env-default:"1s"`
+	RefreshTokenHashing  string        `yaml:"refresh_token_hashing" env-default:"bcrypt"`
+	RefreshTokenPepper   string        `env:"REFRESH_TOKEN_PEPPER"`
+	TrustedProxies       []string      `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" env-separator:","`
+	ClientIPHeaders      []string      `yaml:"client_ip_headers" env-default:"X-Forwarded-For,Forwarded,X-Real-IP"`
+	// ShutdownTimeout bounds the whole shutdown sequence, ShutdownDelay is how long the instance
+	// keeps serving after reporting unready, so load balancers stop routing to it first.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"30s"`
+	ShutdownDelay   time.Duration `yaml:"shutdown_delay" env:"SHUTDOWN_DELAY"`
+	RefreshCookie   Cookie        `yaml:"refresh_cookie"`
+}
+
+// Cookie is the refresh cookie policy. The cookie is set once per path,
+// so that every endpoint consuming the refresh token receives it.
+// Secure and HttpOnly are on unless explicitly dropped.
+type Cookie struct {
+	Name     string   `yaml:"name" env-default:"refresh_session"`
+	Domain   string   `yaml:"domain" env:"COOKIE_DOMAIN"`
+	Paths    []string `yaml:"paths" env-default:"/api/auth/refresh,/api/auth/logout"`
+	SameSite string   `yaml:"same_site" env-default:"strict"`
+	// Insecure drops the Secure attribute, for development over plain HTTP only.
+	Insecure bool `yaml:"insecure" env:"COOKIE_INSECURE"`
+	// ScriptAccess drops the HttpOnly attribute.
+	ScriptAccess bool `yaml:"script_access"`
+	// HostPrefix names the cookie with the __Host- prefix, which requires Secure, no Domain and the "/" path.
+	HostPrefix bool `yaml:"host_prefix"`
+	// MaxAge caps the cookie lifetime, zero keeps it until the refresh token expires.
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+type Session struct {
+	IPChangePolicy string `yaml:"ip_change_policy" env-default:"notify_allow"`
+	// MaxPerUser caps concurrent sessions of a user, the oldest are evicted first. Zero means no limit.
+	MaxPerUser int `yaml:"max_per_user" env-default:"10"`
+	// RefreshGracePeriod is how long a just rotated token pair may be refreshed again,
+	// getting the same new pair back instead of being treated as reuse. Zero disables it.
+	RefreshGracePeriod time.Duration `yaml:"refresh_grace_period" env-default:"10s"`
+	// RevocationCheck makes protected routes reject access tokens of deleted sessions,
+	// trading a cached session lookup for revocation taking effect within RevocationCacheTTL.
+	// Not-before epochs are always checked, cached for the same TTL.
+	RevocationCheck    bool          `yaml:"revocation_check" env:"REVOCATION_CHECK"`
+	RevocationCacheTTL time.Duration `yaml:"revocation_cache_ttl" env-default:"5s"`
+	// Expired sessions are deleted every SweepInterval, SweepBatchSize rows per statement,
+	// by whichever replica takes the sweep lock.
+	SweepInterval  time.Duration `yaml:"sweep_interval" env:"SWEEP_INTERVAL" env-default:"10m"`
+	SweepBatchSize int           `yaml:"sweep_batch_size" env-default:"1000"`
+}
+
+type Notifier struct {
+	Timeout time.Duration `yaml:"timeout" env-default:"10s"`
+	// QueueSize bounds the events waiting for delivery, events beyond it are dropped.
+	QueueSize int     `yaml:"queue_size" env-default:"100"`
+	SMTP      SMTP    `yaml:"smtp"`
+	Webhook   Webhook `yaml:"webhook"`
+}
+
+type SMTP struct {
+	Host     string   `yaml:"host" env:"SMTP_HOST"`
+	Port     string   `yaml:"port" env:"SMTP_PORT" env-default:"587"`
+	Username string   `env:"SMTP_USERNAME"`
+	Password string   `env:"SMTP_PASSWORD"`
+	From     string   `yaml:"from" env:"SMTP_FROM"`
+	To       []string `yaml:"to" env:"SMTP_TO" env-separator:","`
+}
+
+type Webhook struct {
+	URL    string `yaml:"url" env:"WEBHOOK_URL"`
+	Secret string `env:"WEBHOOK_SECRET"`
+}
+
+// CSRF protects the endpoints consuming the refresh cookie.
+// Its token is an HMAC of the refresh token, issued in a cookie readable by scripts and in a response
+// header exposed to CORS origins, and expected back in a request header of the same name. Origin and Referer must be same-origin or trusted.
+type CSRF struct {
+	Enabled        bool     `yaml:"enabled" env:"CSRF_ENABLED"`
+	Secret         string   `env:"CSRF_SECRET"`
+	CookieName     string   `yaml:"cookie_name" env-default:"csrf_token"`
+	HeaderName     string   `yaml:"header_name" env-default:"X-CSRF-Token"`
+	TrustedOrigins []string `yaml:"trusted_origins" env:"CSRF_TRUSTED_ORIGINS" env-separator:","`
+}
+
+// CORS lets browser front-ends on other origins call the API.
+// Origins are exact ("https://app.example.com"), subdomain patterns ("https://*.example.com") or "*".
+// No allowed origins disables CORS.
+type CORS struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" env-separator:","`
+	AllowedMethods   []string      `yaml:"allowed_methods" env-default:"GET,POST,DELETE"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" env-default:"Authorization,Content-Type,X-CSRF-Token"`
+	ExposedHeaders   []string      `yaml:"exposed_headers" env-default:"Retry-After"`
+	AllowCredentials bool          `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+	MaxAge           time.Duration `yaml:"max_age" env-default:"10m"`
+}
+
+// Admin guards the admin endpoints with a static bearer token. Without a token they are disabled.
+type Admin struct {
+	Token string `env:"ADMIN_TOKEN"`
+}
+
+// OAuth configures the token introspection (RFC 7662) and revocation (RFC 7009) endpoints.
+type OAuth struct {
+	// Clients are "id:secret" credentials of the resource servers allowed to call the endpoints.
+	Clients []string `env:"OAUTH_CLIENTS" env-separator:","`
+	// ClientID and Scope are reported for the tokens, all issued to the service's own login flow.
+	ClientID string `yaml:"client_id" env-default:"auth-service"`
+	Scope    string `yaml:"scope" env-default:"auth"`
+	// RevokingClients may revoke tokens. The tokens are not issued to any client, so there is no
+	// binding to check: revoking any user's session is a privilege granted per client, denied by default.
+	RevokingClients []string `yaml:"revoking_clients" env:"OAUTH_REVOKING_CLIENTS" env-separator:","`
+}
+
+// Metrics exposes Prometheus metrics on a listener of their own, kept off the public API.
+type Metrics struct {
+	Enabled bool   `yaml:"enabled" env:"METRICS_ENABLED"`
+	Path    string `yaml:"path" env-default:"/metrics"`
+	Address string `yaml:"address" env:"METRICS_ADDRESS" env-default:":9090"`
+	// OnAPIListener serves the metrics on the API listener instead of the address, opted into
+	// only when the API is not reachable from outside or the path is filtered in front of it.
+	OnAPIListener bool `yaml:"on_api_listener" env:"METRICS_ON_API_LISTENER"`
+}
+
+// Tracing exports OpenTelemetry traces. The OTLP exporter is set up by the standard OTEL_EXPORTER_OTLP_* variables.
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"`
+	SampleRatio float64 `yaml:"sample_ratio" env-default:"1"`
+}
+
+// KeyRing configures the database backed signing key ring.
+// A zero rotation interval leaves rotation to the keys CLI.
+type KeyRing struct {
+	ReloadInterval     time.Duration `yaml:"reload_interval" env-default:"30s"`
+	RotationInterval   time.Duration `yaml:"rotation_interval" env:"KEY_ROTATION_INTERVAL"`
+	RotationPrepublish time.Duration `yaml:"rotation_prepublish" env-default:"5m"`
+	RotationAlgorithm  string        `yaml:"rotation_algorithm"`
+	// EncryptionKey is a base64 encoded 32-byte key encrypting stored signing keys at rest.
+	// Without it they are stored as plaintext PEM, so database read access suffices to mint tokens.
+	EncryptionKey string `env:"KEY_ENCRYPTION_KEY"`
 }
 
 type DBConn struct {
 	URL          string `env:"DB_URL" env-required:"true"`
 	MaxOpenConns int    `yaml:"max_open_conns" env-default:"15"`
+	// SkipMigrations leaves the schema to the "migrate" command instead of migrating up on connect.
+	SkipMigrations bool `yaml:"skip_migrations" env:"DB_SKIP_MIGRATIONS"`
 }
 
 func New(path string) (*Config, error) {
@@ -32,5 +225,157 @@ func New(path string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
+
+func (c *Config) validate() error {
+	if c.KeyRing.RotationAlgorithm == "" {
+		c.KeyRing.RotationAlgorithm = c.Server.AccessTokenAlgorithm
+	}
+
+	if c.KeyRing.RotationInterval > 0 && c.KeyRing.RotationPrepublish < c.KeyRing.ReloadInterval {
+		return fmt.Errorf("config: key rotation prepublish period must not be shorter than the reload interval")
+	}
+
+	if c.Session.SweepInterval <= 0 || c.Session.SweepBatchSize <= 0 {
+		return fmt.Errorf("config: session sweep interval and batch size must be positive")
+	}
+
+	switch c.Server.RefreshTokenHashing {
+	case HashingBcrypt:
+	case HashingHMACSHA256:
+		if len(c.Server.RefreshTokenPepper) < minPepperLength {
+			return fmt.Errorf("config: %s hashing requires REFRESH_TOKEN_PEPPER of at least %d characters",
+				HashingHMACSHA256, minPepperLength)
+		}
+	default:
+		return fmt.Errorf("config: unknown refresh token hashing %q", c.Server.RefreshTokenHashing)
+	}
+
+	if err := c.Server.RefreshCookie.validate(); err != nil {
+		return err
+	}
+
+	if c.CSRF.Enabled && len(c.CSRF.Secret) < minCSRFSecretLength {
+		return fmt.Errorf("config: csrf protection requires CSRF_SECRET of at least %d characters", minCSRFSecretLength)
+	}
+
+	if c.Admin.Token != "" && len(c.Admin.Token) < minAdminTokenLength {
+		return fmt.Errorf("config: ADMIN_TOKEN must be at least %d characters", minAdminTokenLength)
+	}
+
+	clientSecrets, err := c.OAuth.ClientSecrets()
+	if err != nil {
+		return err
+	}
+	for _, clientID := range c.OAuth.RevokingClients {
+		if _, ok := clientSecrets[clientID]; !ok {
+			return fmt.Errorf("config: revoking oauth client %q has no credentials", clientID)
+		}
+	}
+
+	switch c.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterOTLP, TracingExporterStdout:
+	default:
+		return fmt.Errorf("config: unknown tracing exporter %q", c.Tracing.Exporter)
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("config: tracing sample ratio must be within [0, 1]")
+	}
+
+	if err := c.CORS.validate(); err != nil {
+		return err
+	}
+
+	switch c.Session.IPChangePolicy {
+	case IPChangePolicyNotifyAllow, IPChangePolicyReject:
+	default:
+		return fmt.Errorf("config: unknown ip change policy %q", c.Session.IPChangePolicy)
+	}
+
+	if c.Notifier.SMTP.Host != "" && (c.Notifier.SMTP.From == "" || len(c.Notifier.SMTP.To) == 0) {
+		return fmt.Errorf("config: smtp notifier requires sender and recipients")
+	}
+
+	if c.Notifier.QueueSize < 0 {
+		return fmt.Errorf("config: notifier queue size must not be negative")
+	}
+
+	return nil
+}
+
+func (c *Cookie) validate() error {
+	if len(c.Paths) == 0 {
+		return fmt.Errorf("config: refresh cookie requires at least one path")
+	}
+
+	switch c.SameSite {
+	case SameSiteLax, SameSiteStrict:
+	case SameSiteNone:
+		if c.Insecure {
+			return fmt.Errorf("config: refresh cookie with SameSite=None must be secure")
+		}
+	default:
+		return fmt.Errorf("config: unknown refresh cookie SameSite mode %q", c.SameSite)
+	}
+
+	if c.HostPrefix && (c.Insecure || c.Domain != "" || len(c.Paths) != 1 || c.Paths[0] != "/") {
+		return fmt.Errorf("config: %s refresh cookie must be secure, without domain and on the \"/\" path only", HostCookiePrefix)
+	}
+
+	return nil
+}
+
+func (c *CORS) validate() error {
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" && c.AllowCredentials {
+			return fmt.Errorf("config: cors can't allow credentials for any origin")
+		}
+		if origin != "*" && strings.Count(origin, "*") > 1 {
+			return fmt.Errorf("config: cors origin %q may have a single wildcard only", origin)
+		}
+	}
+
+	return nil
+}
+
+// ClientSecrets parses the client credentials into secrets by client ID.
+func (o *OAuth) ClientSecrets() (map[string]string, error) {
+	secrets := make(map[string]string, len(o.Clients))
+	for _, client := range o.Clients {
+		id, secret, ok := strings.Cut(client, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("config: oauth client credentials must be \"id:secret\" pairs")
+		}
+		secrets[id] = secret
+	}
+
+	return secrets, nil
+}
+
+// CookieName is the refresh cookie name, including the __Host- prefix when enabled.
+func (c *Cookie) CookieName() string {
+	if c.HostPrefix {
+		return HostCookiePrefix + c.Name
+	}
+	return c.Name
+}
+
+// HasStaticKey reports whether the server config itself provides a signing key.
+func (s *Server) HasStaticKey() bool {
+	if s.AccessTokenAlgorithm == "HS512" {
+		return s.AccessTokenSecretKey != ""
+	}
+	return s.AccessTokenKeyFile != ""
+}
+
+// KeyRetention is how long a demoted signing key must keep verifying: until the last
+// access token it signed expires. Refresh relies on the refresh token alone, so it needs no longer.
+func (s *Server) KeyRetention() time.Duration {
+	return s.AccessTokenExpire
+}
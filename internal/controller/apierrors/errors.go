@@ -5,11 +5,15 @@ import "errors"
 // API errors.
 var (
 	ErrAuthenticationFailed = errors.New("authentication failed")
-	ErrAuthorizeNotNeeded   = errors.New("authorization is not needed")
 	ErrNoRefreshToken       = errors.New("no refresh token")
-	ErrNoAccessToken        = errors.New("no access token to refresh")
 	ErrRefreshUnavalible    = errors.New("refresh unavailible")
 	ErrInvalidRequestFormat = errors.New("invalid request format")
 	ErrInvalidRequestData   = errors.New("invalid request data")
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrForgeryCheckFailed   = errors.New("request forgery check failed")
+	ErrClientAuthentication = errors.New("client authentication failed")
+	ErrNoToken              = errors.New("invalid_request: no token")
+	ErrUnauthorizedClient   = errors.New("unauthorized_client: client may not revoke tokens")
+	ErrServiceOverloaded    = errors.New("service is overloaded, try again later")
 	ErrSomethingWentWrong   = errors.New("sorry, something went wrong")
 )
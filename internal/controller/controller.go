@@ -1,8 +1,10 @@
 package controller
 
 import (
+	"auth-service/internal/clientip"
 	"auth-service/internal/controller/apierrors"
 	"auth-service/internal/controller/responser"
+	"auth-service/internal/health"
 	"auth-service/internal/mappers/dtomap"
 	"auth-service/internal/mappers/modelmap"
 	"auth-service/internal/middleware"
@@ -11,7 +13,8 @@ import (
 	"auth-service/internal/types/dto"
 	"log/slog"
 	"net/http"
-	"strings"
+
+	"github.com/gorilla/mux"
 )
 
 type Controller interface {
@@ -19,21 +22,51 @@ type Controller interface {
 	HandleGetCurrentUser() http.HandlerFunc
 	HandleRefresh() http.HandlerFunc
 	HandleLogout() http.HandlerFunc
+	HandleJWKS() http.HandlerFunc
+	HandleListSessions() http.HandlerFunc
+	HandleRevokeSession() http.HandlerFunc
+	HandleRevokeOtherSessions() http.HandlerFunc
+	HandleBumpNotBefore() http.HandlerFunc
+	HandleIntrospect() http.HandlerFunc
+	HandleRevoke() http.HandlerFunc
+	HandleHealthz() http.HandlerFunc
+	HandleReadyz() http.HandlerFunc
 }
 
 type authController struct {
-	service service.Service
-	logger  *slog.Logger
+	service    service.Service
+	tokenizer  tokenizer.Tokenizer
+	mw         middleware.Middleware
+	ipResolver clientip.Resolver
+	health     health.Checker
+	logger     *slog.Logger
 }
 
-func New(service service.Service, logger *slog.Logger) Controller {
+func New(service service.Service, tok tokenizer.Tokenizer, mw middleware.Middleware, ipResolver clientip.Resolver,
+	health health.Checker, logger *slog.Logger) Controller {
 	return &authController{
-		service: service,
-		logger:  logger,
+		service:    service,
+		tokenizer:  tok,
+		mw:         mw,
+		ipResolver: ipResolver,
+		health:     health,
+		logger:     logger,
 	}
 }
 
-const GUIDQueryParam = "guid"
+const (
+	GUIDQueryParam   = "guid"
+	SessionIDPathVar = "session_id"
+
+	TokenFormParam         = "token"
+	TokenTypeHintFormParam = "token_type_hint"
+)
+
+const (
+	cacheControlHeader = "Cache-Control"
+	jwksCacheControl   = "public, max-age=300"
+	noStoreControl     = "no-store"
+)
 
 func (c *authController) HandleLogin() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -47,7 +80,7 @@ func (c *authController) HandleLogin() http.HandlerFunc {
 			UserGUID: r.URL.Query().Get(GUIDQueryParam),
 		}
 
-		clientIP := getClientIPFromRequest(r)
+		clientIP := c.ipResolver.ClientIP(r)
 
 		response, refreshCookie, err := c.service.Login(r.Context(), modelmap.MapToLoginModel(&request, r.UserAgent(), clientIP))
 		if err != nil {
@@ -59,7 +92,7 @@ func (c *authController) HandleLogin() http.HandlerFunc {
 			return
 		}
 
-		http.SetCookie(w, refreshCookie)
+		c.setRefreshCookie(w, refreshCookie)
 		responser.MakeResponseJSON(w, http.StatusOK, &response)
 	}
 }
@@ -82,20 +115,15 @@ func (c *authController) HandleGetCurrentUser() http.HandlerFunc {
 
 func (c *authController) HandleRefresh() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		accessToken := strings.TrimPrefix(r.Header.Get(middleware.AuthorizationHeader), "Bearer ")
-		if accessToken == "" {
-			responser.MakeErrorResponseJSON(w, dtomap.MapToErrorResponse(apierrors.ErrNoAccessToken, http.StatusBadRequest))
-			return
-		}
-		refreshCookie, err := r.Cookie(tokenizer.RefreshCookieName)
+		refreshCookie, err := r.Cookie(c.tokenizer.RefreshCookieName())
 		if err != nil {
 			responser.MakeErrorResponseJSON(w, dtomap.MapToErrorResponse(apierrors.ErrNoRefreshToken, http.StatusBadRequest))
 			return
 		}
 
-		clientIP := getClientIPFromRequest(r)
+		clientIP := c.ipResolver.ClientIP(r)
 
-		response, refreshCookie, err := c.service.Refresh(r.Context(), modelmap.MapToRefreshModel(accessToken, refreshCookie.Value, r.UserAgent(), clientIP))
+		response, refreshCookie, err := c.service.Refresh(r.Context(), modelmap.MapToRefreshModel(refreshCookie.Value, r.UserAgent(), clientIP))
 		if err != nil {
 			apierr := getAPIError(err)
 			if apierr.Code == http.StatusInternalServerError {
@@ -105,14 +133,14 @@ func (c *authController) HandleRefresh() http.HandlerFunc {
 			return
 		}
 
-		http.SetCookie(w, refreshCookie)
+		c.setRefreshCookie(w, refreshCookie)
 		responser.MakeResponseJSON(w, http.StatusOK, &response)
 	}
 }
 
 func (c *authController) HandleLogout() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := c.service.Logout(r.Context(), r.UserAgent())
+		err := c.service.Logout(r.Context())
 		if err != nil {
 			apierr := getAPIError(err)
 			if apierr.Code == http.StatusInternalServerError {
@@ -122,6 +150,169 @@ func (c *authController) HandleLogout() http.HandlerFunc {
 			return
 		}
 
+		c.setRefreshCookie(w, c.tokenizer.ExpiredRefreshTokenCookie())
 		responser.MakeResponseJSON(w, http.StatusOK, nil)
 	}
 }
+
+func (c *authController) HandleJWKS() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := c.service.GetJWKS(r.Context())
+
+		w.Header().Set(cacheControlHeader, jwksCacheControl)
+		responser.MakeResponseJSON(w, http.StatusOK, &response)
+	}
+}
+
+func (c *authController) HandleListSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, err := c.service.ListSessions(r.Context())
+		if err != nil {
+			apierr := getAPIError(err)
+			if apierr.Code == http.StatusInternalServerError {
+				c.logger.Error(err.Error())
+			}
+			responser.MakeErrorResponseJSON(w, apierr)
+			return
+		}
+
+		responser.MakeResponseJSON(w, http.StatusOK, &response)
+	}
+}
+
+func (c *authController) HandleRevokeSession() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := c.service.RevokeSession(r.Context(), mux.Vars(r)[SessionIDPathVar])
+		if err != nil {
+			apierr := getAPIError(err)
+			if apierr.Code == http.StatusInternalServerError {
+				c.logger.Error(err.Error())
+			}
+			responser.MakeErrorResponseJSON(w, apierr)
+			return
+		}
+
+		responser.MakeResponseJSON(w, http.StatusOK, nil)
+	}
+}
+
+func (c *authController) HandleRevokeOtherSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := c.service.RevokeOtherSessions(r.Context())
+		if err != nil {
+			apierr := getAPIError(err)
+			if apierr.Code == http.StatusInternalServerError {
+				c.logger.Error(err.Error())
+			}
+			responser.MakeErrorResponseJSON(w, apierr)
+			return
+		}
+
+		responser.MakeResponseJSON(w, http.StatusOK, nil)
+	}
+}
+
+func (c *authController) HandleBumpNotBefore() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, err := c.service.BumpNotBefore(r.Context(), r.URL.Query().Get(GUIDQueryParam))
+		if err != nil {
+			apierr := getAPIError(err)
+			if apierr.Code == http.StatusInternalServerError {
+				c.logger.Error(err.Error())
+			}
+			responser.MakeErrorResponseJSON(w, apierr)
+			return
+		}
+
+		responser.MakeResponseJSON(w, http.StatusOK, &response)
+	}
+}
+
+func (c *authController) HandleIntrospect() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseForm()
+		if err != nil {
+			responser.MakeErrorResponseJSON(w, dtomap.MapToErrorResponse(apierrors.ErrInvalidRequestFormat, http.StatusBadRequest))
+			return
+		}
+
+		request := dto.TokenRequest{
+			Token:         r.PostForm.Get(TokenFormParam),
+			TokenTypeHint: r.PostForm.Get(TokenTypeHintFormParam),
+		}
+		if request.Token == "" {
+			responser.MakeErrorResponseJSON(w, dtomap.MapToErrorResponse(apierrors.ErrNoToken, http.StatusBadRequest))
+			return
+		}
+
+		response, err := c.service.Introspect(r.Context(), modelmap.MapToTokenRequestModel(&request))
+		if err != nil {
+			apierr := getAPIError(err)
+			if apierr.Code == http.StatusInternalServerError {
+				c.logger.Error(err.Error())
+			}
+			responser.MakeErrorResponseJSON(w, apierr)
+			return
+		}
+
+		w.Header().Set(cacheControlHeader, noStoreControl)
+		responser.MakeResponseJSON(w, http.StatusOK, &response)
+	}
+}
+
+// HandleRevoke answers 200 for unknown and invalid tokens too, as RFC 7009 requires,
+// but the token parameter itself is required.
+func (c *authController) HandleRevoke() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseForm()
+		if err != nil {
+			responser.MakeErrorResponseJSON(w, dtomap.MapToErrorResponse(apierrors.ErrInvalidRequestFormat, http.StatusBadRequest))
+			return
+		}
+
+		request := dto.TokenRequest{
+			Token:         r.PostForm.Get(TokenFormParam),
+			TokenTypeHint: r.PostForm.Get(TokenTypeHintFormParam),
+		}
+
+		if request.Token == "" {
+			responser.MakeErrorResponseJSON(w, dtomap.MapToErrorResponse(apierrors.ErrNoToken, http.StatusBadRequest))
+			return
+		}
+
+		err = c.service.Revoke(r.Context(), modelmap.MapToTokenRequestModel(&request))
+		if err != nil {
+			apierr := getAPIError(err)
+			if apierr.Code == http.StatusInternalServerError {
+				c.logger.Error(err.Error())
+			}
+			responser.MakeErrorResponseJSON(w, apierr)
+			return
+		}
+
+		responser.MakeResponseJSON(w, http.StatusOK, nil)
+	}
+}
+
+// HandleHealthz reports the process is alive, it checks no dependencies.
+func (c *authController) HandleHealthz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(cacheControlHeader, noStoreControl)
+		responser.MakeResponseJSON(w, http.StatusOK, &dto.HealthResponse{Status: health.StatusOK})
+	}
+}
+
+// HandleReadyz reports whether the instance can take traffic, with the result of every check.
+func (c *authController) HandleReadyz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, ready := c.health.Ready(r.Context())
+
+		code := http.StatusOK
+		if !ready {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set(cacheControlHeader, noStoreControl)
+		responser.MakeResponseJSON(w, code, response)
+	}
+}
@@ -7,21 +7,27 @@ import (
 	"auth-service/internal/types/dto"
 	"errors"
 	"net/http"
-	"strings"
 )
 
-const XRealIPHeader = "X-Real-IP"
+const overloadRetryAfterSeconds = 1
 
 func getAPIError(err error) *dto.ErrorResponse {
-	if errors.Is(err, serverrors.ErrSessionAlreadyExists) {
-		return dtomap.MapToErrorResponse(apierrors.ErrAuthorizeNotNeeded, http.StatusBadRequest)
-	} else if errors.Is(err, serverrors.ErrUserGUIDInvalid) {
+	if errors.Is(err, serverrors.ErrUserGUIDInvalid) ||
+		errors.Is(err, serverrors.ErrSessionIDInvalid) {
 		return dtomap.MapToErrorResponse(apierrors.ErrInvalidRequestData, http.StatusBadRequest)
+	} else if errors.Is(err, serverrors.ErrHashingOverloaded) {
+		apierr := dtomap.MapToErrorResponse(apierrors.ErrServiceOverloaded, http.StatusServiceUnavailable)
+		apierr.RetryAfter = overloadRetryAfterSeconds
+		return apierr
+	} else if errors.Is(err, serverrors.ErrSessionNotFound) {
+		return dtomap.MapToErrorResponse(apierrors.ErrSessionNotFound, http.StatusNotFound)
+	} else if errors.Is(err, serverrors.ErrClientNotAllowed) {
+		return dtomap.MapToErrorResponse(apierrors.ErrUnauthorizedClient, http.StatusForbidden)
 	} else if errors.Is(err, serverrors.ErrNoRefreshSession) {
 		return dtomap.MapToErrorResponse(apierrors.ErrRefreshUnavalible, http.StatusUnauthorized)
-	} else if errors.Is(err, serverrors.ErrOldAccessTokenInvalid) ||
-		errors.Is(err, serverrors.ErrRefreshTokenInvalid) ||
-		errors.Is(err, serverrors.ErrTokenPairInvalid) {
+	} else if errors.Is(err, serverrors.ErrRefreshTokenInvalid) ||
+		errors.Is(err, serverrors.ErrRefreshTokenReused) ||
+		errors.Is(err, serverrors.ErrSessionIPChanged) {
 
 		return dtomap.MapToErrorResponse(apierrors.ErrAuthenticationFailed, http.StatusForbidden)
 	} else {
@@ -29,18 +35,14 @@ func getAPIError(err error) *dto.ErrorResponse {
 	}
 }
 
-func getClientIPFromRequest(r *http.Request) string {
-	splittedRemoteAddress := strings.Split(r.RemoteAddr, ":")
-
-	clientIP := ""
-	if len(splittedRemoteAddress) == 2 {
-		clientIP = splittedRemoteAddress[0]
-	}
-
-	xrealIP := r.Header.Get(XRealIPHeader)
-	if xrealIP != "" {
-		clientIP = xrealIP
+// setRefreshCookie sets the refresh cookie on every path of the cookie policy,
+// along with the CSRF token bound to it.
+func (c *authController) setRefreshCookie(w http.ResponseWriter, cookie *http.Cookie) {
+	for _, path := range c.tokenizer.RefreshCookiePaths() {
+		pathCookie := *cookie
+		pathCookie.Path = path
+		http.SetCookie(w, &pathCookie)
 	}
 
-	return clientIP
+	c.mw.SetCSRFToken(w, cookie)
 }
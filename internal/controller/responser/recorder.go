@@ -0,0 +1,26 @@
+package responser
+
+import "net/http"
+
+// StatusRecorder remembers the status code written through it, for instrumenting middlewares.
+type StatusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{
+		ResponseWriter: w,
+		status:         http.StatusOK,
+	}
+}
+
+func (r *StatusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Status is the status code written, 200 when the handler never wrote one.
+func (r *StatusRecorder) Status() int {
+	return r.status
+}
@@ -4,11 +4,13 @@ import (
 	"auth-service/internal/types/dto"
 	"encoding/json"
 	"net/http"
+	"strconv"
 )
 
 const (
 	contentTypeHeader = "Content-Type"
 	contentTypeJSON   = "application/json"
+	retryAfterHeader  = "Retry-After"
 )
 
 func MakeResponseJSON(w http.ResponseWriter, code int, data any) {
@@ -20,5 +22,8 @@ func MakeResponseJSON(w http.ResponseWriter, code int, data any) {
 }
 
 func MakeErrorResponseJSON(w http.ResponseWriter, apierr *dto.ErrorResponse) {
+	if apierr.RetryAfter > 0 {
+		w.Header().Set(retryAfterHeader, strconv.Itoa(apierr.RetryAfter))
+	}
 	MakeResponseJSON(w, apierr.Code, apierr)
 }
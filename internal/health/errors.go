@@ -0,0 +1,13 @@
+package health
+
+import "errors"
+
+// Readiness check errors, reported in the readiness response.
+var (
+	ErrShuttingDown   = errors.New("health: shutting down")
+	ErrDatabase       = errors.New("health: database unreachable")
+	ErrSchemaDirty    = errors.New("health: schema migration left dirty")
+	ErrSchemaOutdated = errors.New("health: schema not at expected version")
+	ErrNoSigningKey   = errors.New("health: no signing key loaded")
+	ErrPoolSaturated  = errors.New("health: hashing pool saturated")
+)
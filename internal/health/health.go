@@ -0,0 +1,146 @@
+package health
+
+import (
+	"auth-service/internal/repository"
+	"auth-service/internal/tokenizer"
+	"auth-service/internal/types/dto"
+	"auth-service/pkg/cryptor"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Names of the readiness checks.
+const (
+	CheckShutdown   = "shutdown"
+	CheckDatabase   = "database"
+	CheckMigrations = "migrations"
+	CheckSigningKey = "signing_key"
+	CheckHashing    = "hashing_pool"
+)
+
+const checkTimeout = 2 * time.Second
+
+// Checker tells whether the service can take traffic.
+type Checker interface {
+	// Ready runs the readiness checks, the service is ready when all of them pass.
+	Ready(ctx context.Context) (*dto.ReadinessResponse, bool)
+	// MarkShuttingDown fails every later readiness check, so the instance is taken out of rotation before it stops.
+	MarkShuttingDown()
+}
+
+type checker struct {
+	repo         repository.Repository
+	keys         tokenizer.KeyRing
+	cryptor      cryptor.Cryptor
+	logger       *slog.Logger
+	shuttingDown atomic.Bool
+}
+
+func New(repo repository.Repository, keys tokenizer.KeyRing, cryptor cryptor.Cryptor, logger *slog.Logger) Checker {
+	return &checker{
+		repo:    repo,
+		keys:    keys,
+		cryptor: cryptor,
+		logger:  logger,
+	}
+}
+
+func (c *checker) MarkShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+func (c *checker) Ready(ctx context.Context) (*dto.ReadinessResponse, bool) {
+	if c.shuttingDown.Load() {
+		return &dto.ReadinessResponse{
+			Status: StatusFail,
+			Checks: map[string]dto.CheckResponse{
+				CheckShutdown: failed(ErrShuttingDown),
+			},
+		}, false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	checks := map[string]error{
+		CheckDatabase:   c.checkDatabase(ctx),
+		CheckMigrations: c.checkMigrations(ctx),
+		CheckSigningKey: c.checkSigningKey(),
+		CheckHashing:    c.checkHashing(),
+	}
+
+	response := &dto.ReadinessResponse{
+		Status: StatusOK,
+		Checks: make(map[string]dto.CheckResponse, len(checks)),
+	}
+	for name, err := range checks {
+		if err == nil {
+			response.Checks[name] = dto.CheckResponse{Status: StatusOK}
+			continue
+		}
+
+		response.Status = StatusFail
+		response.Checks[name] = failed(err)
+	}
+
+	return response, response.Status == StatusOK
+}
+
+func (c *checker) checkDatabase(ctx context.Context) error {
+	if err := c.repo.Ping(ctx); err != nil {
+		c.logger.Warn("readiness check failed", slog.String("check", CheckDatabase), slog.String("error", err.Error()))
+		return ErrDatabase
+	}
+
+	return nil
+}
+
+func (c *checker) checkMigrations(ctx context.Context) error {
+	version, err := c.repo.SchemaVersion(ctx)
+	if err != nil {
+		c.logger.Warn("readiness check failed", slog.String("check", CheckMigrations), slog.String("error", err.Error()))
+		return ErrDatabase
+	}
+
+	if version.Dirty {
+		return fmt.Errorf("%w: version %d", ErrSchemaDirty, version.Current)
+	}
+	if version.Current != version.Expected {
+		return fmt.Errorf("%w: at %d, expected %d", ErrSchemaOutdated, version.Current, version.Expected)
+	}
+
+	return nil
+}
+
+func (c *checker) checkSigningKey() error {
+	if c.keys.SigningKey() == nil {
+		return ErrNoSigningKey
+	}
+
+	return nil
+}
+
+// checkHashing fails when every worker is busy and the queue is full, new hashing jobs would be shed.
+func (c *checker) checkHashing() error {
+	stats := c.cryptor.Stats()
+	if stats.Workers > 0 && stats.Busy >= stats.Workers && stats.Queued >= stats.QueueCapacity {
+		return fmt.Errorf("%w: %d busy workers, %d queued jobs", ErrPoolSaturated, stats.Busy, stats.Queued)
+	}
+
+	return nil
+}
+
+func failed(err error) dto.CheckResponse {
+	return dto.CheckResponse{
+		Status: StatusFail,
+		Error:  err.Error(),
+	}
+}
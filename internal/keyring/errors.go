@@ -0,0 +1,12 @@
+package keyring
+
+import "errors"
+
+// Key ring errors.
+var (
+	ErrReload               = errors.New("keyring: reload failed")
+	ErrRotation             = errors.New("keyring: rotation failed")
+	ErrEncryptionKeyInvalid = errors.New("keyring: invalid key encryption key")
+	ErrSealing              = errors.New("keyring: key material encryption failed")
+	ErrUnsealing            = errors.New("keyring: key material decryption failed")
+)
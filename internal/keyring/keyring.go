@@ -0,0 +1,264 @@
+package keyring
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/repository"
+	"auth-service/internal/repository/repoerrors"
+	"auth-service/internal/tokenizer"
+	"auth-service/internal/types/queries"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Ring is a key ring backed by the signing_keys table: one active signing key
+// plus any number of verification-only keys, reloaded periodically so every replica
+// converges on the same set without restarts.
+type Ring interface {
+	tokenizer.KeyRing
+	// Run reloads the ring and performs scheduled rotation until ctx is done.
+	Run(ctx context.Context)
+}
+
+type keyRing struct {
+	cfg       config.KeyRing
+	repo      repository.Repository
+	sealer    Sealer
+	static    *tokenizer.Key
+	retention time.Duration
+	logger    *slog.Logger
+	current   atomic.Pointer[snapshot]
+}
+
+type snapshot struct {
+	signing *tokenizer.Key
+	byID    map[string]*tokenizer.Key
+	ordered []*tokenizer.Key
+}
+
+// New loads the ring. The static key from the server config, if any, signs only while
+// the database holds no active key and always stays available for verification.
+// Demoted keys keep verifying tokens for the retention period.
+// Stored key material is encrypted and decrypted by the sealer.
+func New(ctx context.Context, cfg config.KeyRing, repo repository.Repository, sealer Sealer, static *tokenizer.Key,
+	retention time.Duration, logger *slog.Logger) (Ring, error) {
+	if !sealer.Enabled() {
+		logger.Warn("signing keys are stored unencrypted, set KEY_ENCRYPTION_KEY to encrypt them at rest")
+	}
+
+	ring := &keyRing{
+		cfg:       cfg,
+		repo:      repo,
+		sealer:    sealer,
+		static:    static,
+		retention: retention,
+		logger:    logger,
+	}
+
+	if cfg.RotationInterval > 0 {
+		if err := ring.rotate(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ring.reload(ctx); err != nil {
+		return nil, err
+	}
+
+	if ring.SigningKey() == nil {
+		return nil, tokenizer.ErrNoSigningKey
+	}
+
+	return ring, nil
+}
+
+func (r *keyRing) SigningKey() *tokenizer.Key {
+	return r.current.Load().signing
+}
+
+func (r *keyRing) VerificationKey(kid string) (*tokenizer.Key, bool) {
+	if kid == "" {
+		return r.static, r.static != nil
+	}
+
+	key, ok := r.current.Load().byID[kid]
+	return key, ok
+}
+
+func (r *keyRing) VerificationKeys() []*tokenizer.Key {
+	return r.current.Load().ordered
+}
+
+func (r *keyRing) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if r.cfg.RotationInterval > 0 {
+			if err := r.rotate(ctx); err != nil {
+				r.logger.Error(err.Error())
+			}
+		}
+
+		if err := r.reload(ctx); err != nil {
+			r.logger.Error(err.Error())
+		}
+	}
+}
+
+func (r *keyRing) reload(ctx context.Context) error {
+	stored, err := r.repo.ListSigningKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrReload, err)
+	}
+
+	next := &snapshot{
+		byID: make(map[string]*tokenizer.Key, len(stored)+1),
+	}
+
+	if r.static != nil {
+		next.signing = r.static
+		next.byID[r.static.ID()] = r.static
+		next.ordered = append(next.ordered, r.static)
+	}
+
+	for _, stored := range stored {
+		material, err := r.sealer.Open(stored.ID, stored.PrivateKey)
+		if err != nil {
+			r.logger.Error("skipping unusable signing key", slog.String("kid", stored.ID), slog.String("error", err.Error()))
+			continue
+		}
+
+		key, err := tokenizer.NewKey(stored.Algorithm, stored.ID, material)
+		if err != nil {
+			r.logger.Error("skipping unusable signing key", slog.String("kid", stored.ID), slog.String("error", err.Error()))
+			continue
+		}
+
+		if stored.Active {
+			next.signing = key
+		}
+		if _, ok := next.byID[key.ID()]; !ok {
+			next.ordered = append(next.ordered, key)
+		}
+		next.byID[key.ID()] = key
+	}
+
+	previous := r.current.Swap(next)
+	if previous != nil && previous.signing != nil && next.signing != nil && previous.signing.ID() != next.signing.ID() {
+		r.logger.Info("signing key changed", slog.String("kid", next.signing.ID()))
+	}
+
+	return nil
+}
+
+// rotate generates a successor once the active key has signed for the rotation interval
+// and promotes it after it has been published for the prepublish period,
+// giving every replica and JWKS consumer time to learn it first.
+func (r *keyRing) rotate(ctx context.Context) error {
+	stored, err := r.repo.ListSigningKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRotation, err)
+	}
+
+	// A static key counts as signing: its successor must be published before it takes over.
+	hasActive := r.static != nil
+	for _, key := range stored {
+		if key.Active {
+			hasActive = true
+			if time.Since(key.ActivatedAt) < r.cfg.RotationInterval {
+				return nil
+			}
+		}
+	}
+
+	for _, key := range stored {
+		if key.ActivatedAt.IsZero() {
+			if hasActive && time.Since(key.CreatedAt) < r.cfg.RotationPrepublish {
+				return nil
+			}
+			return r.promote(ctx, key.ID)
+		}
+	}
+
+	key, err := AddKey(ctx, r.repo, r.sealer, r.cfg.RotationAlgorithm, nil)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrAlreadyExists) {
+			// Another replica generated the successor concurrently.
+			return nil
+		}
+		return fmt.Errorf("%w: %w", ErrRotation, err)
+	}
+	r.logger.Info("generated successor signing key", slog.String("kid", key.ID()))
+
+	// With nothing signing yet there is no one to publish the key to in advance.
+	if !hasActive {
+		return r.promote(ctx, key.ID())
+	}
+
+	return nil
+}
+
+func (r *keyRing) promote(ctx context.Context, kid string) error {
+	err := PromoteKey(ctx, r.repo, kid, r.retention)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRotation, err)
+	}
+	r.logger.Info("promoted signing key", slog.String("kid", kid))
+
+	return r.repo.DeleteExpiredSigningKeys(ctx)
+}
+
+// AddKey stores a verification-only key, generated when no material is given, sealing its material.
+func AddKey(ctx context.Context, repo repository.Repository, sealer Sealer, alg string, material []byte) (*tokenizer.Key, error) {
+	if material == nil {
+		generated, err := tokenizer.GenerateKeyMaterial(alg)
+		if err != nil {
+			return nil, err
+		}
+		material = generated
+	}
+
+	key, err := tokenizer.NewKey(alg, "", material)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := sealer.Seal(key.ID(), material)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.CreateSigningKey(ctx, &queries.CreateSigningKeyQuery{
+		ID:         key.ID(),
+		Algorithm:  key.Algorithm(),
+		PrivateKey: sealed,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// PromoteKey makes the key the signing one; the previously active key keeps verifying for the retention period.
+func PromoteKey(ctx context.Context, repo repository.Repository, kid string, retention time.Duration) error {
+	return repo.PromoteSigningKey(ctx, &queries.PromoteSigningKeyQuery{
+		ID:                 kid,
+		DemotedVerifyUntil: time.Now().Add(retention),
+	})
+}
+
+// RetireKey removes an inactive key; tokens signed with it stop verifying once replicas reload.
+func RetireKey(ctx context.Context, repo repository.Repository, kid string) error {
+	return repo.RetireSigningKey(ctx, kid)
+}
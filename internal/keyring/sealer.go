@@ -0,0 +1,96 @@
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+const (
+	encryptionKeyBytes = 32
+	// sealedPrefix marks key material encrypted at rest, rows without it are read as plaintext PEM.
+	sealedPrefix = "aes256gcm:"
+)
+
+// Sealer encrypts signing key material before it is stored, with AES-256-GCM under a key
+// encryption key from the environment. The key ID is authenticated along with the material,
+// so a sealed key cannot be moved to another row.
+type Sealer interface {
+	Seal(kid string, material []byte) (string, error)
+	Open(kid, stored string) ([]byte, error)
+	// Enabled reports whether new keys are encrypted, without an encryption key they are stored as is.
+	Enabled() bool
+}
+
+type aeadSealer struct {
+	aead cipher.AEAD
+}
+
+// NewSealer builds a sealer from a base64 encoded 32-byte key, an empty one disables encryption.
+func NewSealer(encryptionKey string) (Sealer, error) {
+	if encryptionKey == "" {
+		return &aeadSealer{}, nil
+	}
+
+	kek, err := base64.StdEncoding.DecodeString(encryptionKey)
+	if err != nil || len(kek) != encryptionKeyBytes {
+		return nil, fmt.Errorf("%w: must be %d base64 encoded bytes", ErrEncryptionKeyInvalid, encryptionKeyBytes)
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEncryptionKeyInvalid, err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEncryptionKeyInvalid, err)
+	}
+
+	return &aeadSealer{aead: aead}, nil
+}
+
+func (s *aeadSealer) Enabled() bool {
+	return s.aead != nil
+}
+
+func (s *aeadSealer) Seal(kid string, material []byte) (string, error) {
+	if s.aead == nil {
+		return string(material), nil
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSealing, err)
+	}
+
+	sealed := s.aead.Seal(nonce, nonce, material, []byte(kid))
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *aeadSealer) Open(kid, stored string) ([]byte, error) {
+	encoded, ok := strings.CutPrefix(stored, sealedPrefix)
+	if !ok {
+		return []byte(stored), nil
+	}
+
+	if s.aead == nil {
+		return nil, fmt.Errorf("%w: key is encrypted but no encryption key is configured", ErrUnsealing)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return nil, fmt.Errorf("%w: malformed key material", ErrUnsealing)
+	}
+
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	material, err := s.aead.Open(nil, nonce, ciphertext, []byte(kid))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnsealing, err)
+	}
+
+	return material, nil
+}
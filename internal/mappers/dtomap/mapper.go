@@ -2,6 +2,8 @@ package dtomap
 
 import (
 	"auth-service/internal/types/dto"
+	"auth-service/internal/types/models"
+	"time"
 )
 
 func MapToLoginResponse(accessToken string) *dto.LoginResponse {
@@ -28,3 +30,88 @@ func MapToRefreshResponse(accessToken string) *dto.RefreshResponse {
 		NewAccessToken: accessToken,
 	}
 }
+
+func MapToSecurityEventPayload(event *models.SecurityEvent) *dto.SecurityEventPayload {
+	return &dto.SecurityEventPayload{
+		Type:       event.Type,
+		UserGUID:   event.UserGUID,
+		SessionID:  event.SessionID,
+		UserAgent:  event.UserAgent,
+		PreviousIP: event.PreviousIP.String(),
+		IP:         event.IP.String(),
+		OccurredAt: event.OccurredAt,
+	}
+}
+
+func MapToJWKSResponse(keys []models.JWK) *dto.JWKSResponse {
+	response := &dto.JWKSResponse{
+		Keys: make([]dto.JWK, 0, len(keys)),
+	}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, dto.JWK{
+			KeyType:   key.KeyType,
+			KeyID:     key.KeyID,
+			Algorithm: key.Algorithm,
+			Use:       key.Use,
+			N:         key.N,
+			E:         key.E,
+			Curve:     key.Curve,
+			X:         key.X,
+			Y:         key.Y,
+		})
+	}
+
+	return response
+}
+
+func MapToSessionsResponse(sessions []*models.Session, currentSessionID string) *dto.SessionsResponse {
+	response := &dto.SessionsResponse{
+		Sessions: make([]dto.SessionResponse, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		item := dto.SessionResponse{
+			ID:        session.ID,
+			UserAgent: session.UserAgent,
+			IP:        session.IP.String(),
+			CreatedAt: session.CreatedAt,
+			ExpiresAt: session.ExpiresAt,
+			Current:   session.ID == currentSessionID,
+		}
+		if !session.RefreshedAt.IsZero() {
+			refreshedAt := session.RefreshedAt
+			item.RefreshedAt = &refreshedAt
+		}
+		response.Sessions = append(response.Sessions, item)
+	}
+
+	return response
+}
+
+func MapToNotBeforeResponse(userGUID string, notBefore time.Time) *dto.NotBeforeResponse {
+	return &dto.NotBeforeResponse{
+		UserGUID:  userGUID,
+		Global:    userGUID == "",
+		NotBefore: notBefore,
+	}
+}
+
+// MapToIntrospectionResponse maps the token info, a nil one stands for an inactive token.
+func MapToIntrospectionResponse(info *models.TokenInfo) *dto.IntrospectionResponse {
+	if info == nil {
+		return &dto.IntrospectionResponse{Active: false}
+	}
+
+	return &dto.IntrospectionResponse{
+		Active:           true,
+		TokenType:        info.Type,
+		Subject:          info.Subject,
+		ExpiresAt:        info.ExpiresAt.Unix(),
+		IssuedAt:         info.IssuedAt.Unix(),
+		Issuer:           info.Issuer,
+		ClientID:         info.ClientID,
+		Scope:            info.Scope,
+		SessionID:        info.Session.ID,
+		SessionCreatedAt: info.Session.CreatedAt.Unix(),
+		SessionExpiresAt: info.Session.ExpiresAt.Unix(),
+	}
+}
@@ -3,9 +3,10 @@ package modelmap
 import (
 	"auth-service/internal/types/dto"
 	"auth-service/internal/types/models"
+	"net/netip"
 )
 
-func MapToLoginModel(request *dto.LoginRequest, ua, ip string) *models.Login {
+func MapToLoginModel(request *dto.LoginRequest, ua string, ip netip.Addr) *models.Login {
 	return &models.Login{
 		UserGUID:  request.UserGUID,
 		UserAgent: ua,
@@ -13,11 +14,17 @@ func MapToLoginModel(request *dto.LoginRequest, ua, ip string) *models.Login {
 	}
 }
 
-func MapToRefreshModel(access, refresh, ua, ip string) *models.Refresh {
+func MapToRefreshModel(refresh, ua string, ip netip.Addr) *models.Refresh {
 	return &models.Refresh{
-		AccessToken:  access,
 		RefreshToken: refresh,
 		UserAgent:    ua,
 		IP:           ip,
 	}
 }
+
+func MapToTokenRequestModel(request *dto.TokenRequest) *models.TokenRequest {
+	return &models.TokenRequest{
+		Token:         request.Token,
+		TokenTypeHint: request.TokenTypeHint,
+	}
+}
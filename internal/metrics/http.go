@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"auth-service/internal/controller/responser"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// InstrumentHTTP records request rate, errors and duration labelled by the route template,
+// so path variables don't blow up the label cardinality. Set it on the root router.
+func (m *Metrics) InstrumentHTTP() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := "unknown"
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			recorder := responser.NewStatusRecorder(w)
+			start := time.Now()
+
+			next.ServeHTTP(recorder, r)
+
+			m.ObserveHTTP(route, r.Method, recorder.Status(), time.Since(start))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "auth"
+
+// Outcome labels of operations, queries, sweeps and notifications. OutcomeSkipped is a sweep
+// left to another replica, OutcomeDropped a notification that did not fit in the queue.
+const (
+	OutcomeOK      = "ok"
+	OutcomeError   = "error"
+	OutcomeSkipped = "skipped"
+	OutcomeDropped = "dropped"
+)
+
+// Metrics holds the service collectors on a registry of its own.
+type Metrics struct {
+	registry        *prometheus.Registry
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	serviceOutcomes *prometheus.CounterVec
+	queryDuration   *prometheus.HistogramVec
+	sweeps          *prometheus.CounterVec
+	sweptSessions   prometheus.Counter
+	notifications   *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		serviceOutcomes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "service",
+			Name:      "operations_total",
+			Help:      "Service operations by outcome, the error kind for failures.",
+		}, []string{"operation", "outcome"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "query_duration_seconds",
+			Help:      "Repository query latency by query and outcome.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"query", "outcome"}),
+		sweeps: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "sweeper",
+			Name:      "runs_total",
+			Help:      "Expired session sweeps by outcome.",
+		}, []string{"outcome"}),
+		sweptSessions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "sweeper",
+			Name:      "deleted_sessions_total",
+			Help:      "Expired sessions deleted by the sweeper.",
+		}),
+		notifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "notifier",
+			Name:      "events_total",
+			Help:      "Security event notifications by outcome.",
+		}, []string{"outcome"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.serviceOutcomes,
+		m.queryDuration,
+		m.sweeps,
+		m.sweptSessions,
+		m.notifications,
+	)
+
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveHTTP(route, method string, code int, elapsed time.Duration) {
+	m.httpRequests.WithLabelValues(route, method, strconv.Itoa(code)).Inc()
+	m.httpDuration.WithLabelValues(route, method).Observe(elapsed.Seconds())
+}
+
+// ObserveOutcome counts a service operation, outcome is OutcomeOK or the kind of its error.
+func (m *Metrics) ObserveOutcome(operation, outcome string) {
+	m.serviceOutcomes.WithLabelValues(operation, outcome).Inc()
+}
+
+func (m *Metrics) ObserveQuery(query string, elapsed time.Duration, err error) {
+	outcome := OutcomeOK
+	if err != nil {
+		outcome = OutcomeError
+	}
+	m.queryDuration.WithLabelValues(query, outcome).Observe(elapsed.Seconds())
+}
+
+// ObserveSweep counts a sweep of expired sessions and the sessions it deleted.
+func (m *Metrics) ObserveSweep(outcome string, deleted int64) {
+	m.sweeps.WithLabelValues(outcome).Inc()
+	m.sweptSessions.Add(float64(deleted))
+}
+
+// ObserveNotification counts a security event delivered, failed or dropped.
+func (m *Metrics) ObserveNotification(outcome string) {
+	m.notifications.WithLabelValues(outcome).Inc()
+}
+
+// GaugeFunc registers a gauge sampled from fn on every scrape.
+func (m *Metrics) GaugeFunc(subsystem, name, help string, fn func() float64) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, fn))
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"auth-service/internal/controller/apierrors"
+	"auth-service/internal/controller/responser"
+	"auth-service/internal/mappers/dtomap"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Admin admits requests bearing the configured admin token. Without one every request is refused.
+func (hub *middlewareHub) Admin() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := strings.TrimPrefix(r.Header.Get(AuthorizationHeader), "Bearer ")
+
+			// Digests keep the comparison constant time regardless of the token length.
+			given, expected := sha256.Sum256([]byte(token)), sha256.Sum256([]byte(hub.adminToken))
+			if hub.adminToken == "" || subtle.ConstantTimeCompare(given[:], expected[:]) != 1 {
+				responser.MakeErrorResponseJSON(w, dtomap.MapToErrorResponse(apierrors.ErrAuthenticationFailed, http.StatusForbidden))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
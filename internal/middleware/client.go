@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"auth-service/internal/controller/apierrors"
+	"auth-service/internal/controller/responser"
+	"auth-service/internal/mappers/dtomap"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	clientIDParam     = "client_id"
+	clientSecretParam = "client_secret"
+
+	wwwAuthenticateHeader = "WWW-Authenticate"
+	clientAuthChallenge   = `Basic realm="auth-service"`
+)
+
+// Client authenticates OAuth clients by HTTP Basic credentials or client_id and client_secret
+// form parameters, putting the client ID into the request context.
+func (hub *middlewareHub) Client() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clientID, clientSecret, ok := r.BasicAuth()
+			if !ok {
+				clientID, clientSecret = r.PostFormValue(clientIDParam), r.PostFormValue(clientSecretParam)
+			}
+
+			if !hub.authenticateClient(clientID, clientSecret) {
+				w.Header().Set(wwwAuthenticateHeader, clientAuthChallenge)
+				responser.MakeErrorResponseJSON(w, dtomap.MapToErrorResponse(apierrors.ErrClientAuthentication, http.StatusUnauthorized))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ClientIDKey, clientID)))
+		})
+	}
+}
+
+func (hub *middlewareHub) authenticateClient(clientID, clientSecret string) bool {
+	secret, ok := hub.clientSecrets[clientID]
+	if !ok || clientSecret == "" {
+		return false
+	}
+
+	given, expected := sha256.Sum256([]byte(clientSecret)), sha256.Sum256([]byte(secret))
+	return subtle.ConstantTimeCompare(given[:], expected[:]) == 1
+}
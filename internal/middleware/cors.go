@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"auth-service/internal/config"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	varyHeader             = "Vary"
+	allowOriginHeader      = "Access-Control-Allow-Origin"
+	allowMethodsHeader     = "Access-Control-Allow-Methods"
+	allowHeadersHeader     = "Access-Control-Allow-Headers"
+	allowCredentialsHeader = "Access-Control-Allow-Credentials"
+	exposeHeadersHeader    = "Access-Control-Expose-Headers"
+	maxAgeHeader           = "Access-Control-Max-Age"
+	requestMethodHeader    = "Access-Control-Request-Method"
+	requestHeadersHeader   = "Access-Control-Request-Headers"
+	anyOrigin              = "*"
+	wildcard               = "*"
+)
+
+// corsPolicy matches request origins against exact origins and wildcard subdomain patterns.
+type corsPolicy struct {
+	cfg       config.CORS
+	anyOrigin bool
+	origins   map[string]struct{}
+	patterns  [][2]string
+	methods   string
+	headers   string
+	exposed   string
+	maxAge    string
+}
+
+func newCORSPolicy(cfg config.CORS) *corsPolicy {
+	policy := &corsPolicy{
+		cfg:     cfg,
+		origins: make(map[string]struct{}),
+		methods: strings.Join(cfg.AllowedMethods, ", "),
+		headers: strings.Join(cfg.AllowedHeaders, ", "),
+		exposed: strings.Join(cfg.ExposedHeaders, ", "),
+		maxAge:  strconv.Itoa(int(cfg.MaxAge.Seconds())),
+	}
+
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		if origin == anyOrigin {
+			policy.anyOrigin = true
+		} else if prefix, suffix, ok := strings.Cut(origin, wildcard); ok {
+			policy.patterns = append(policy.patterns, [2]string{prefix, suffix})
+		} else {
+			policy.origins[origin] = struct{}{}
+		}
+	}
+
+	return policy
+}
+
+func (p *corsPolicy) allowed(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if _, ok := p.origins[origin]; ok {
+		return true
+	}
+
+	// "https://*.example.com" matches any subdomain, but not example.com itself.
+	for _, pattern := range p.patterns {
+		prefix, suffix := pattern[0], pattern[1]
+		if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) &&
+			!strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CORS answers preflights and sets CORS headers for allowed origins.
+// It wraps the whole router: mux never matches OPTIONS against method-bound routes,
+// and preflights carry no credentials to pass the Auth middleware with.
+// It passes everything through when no origins are allowed.
+func (hub *middlewareHub) CORS() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if hub.cors == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add(varyHeader, originHeader)
+
+			origin := r.Header.Get(originHeader)
+			preflight := r.Method == http.MethodOptions && r.Header.Get(requestMethodHeader) != ""
+
+			if origin == "" || !hub.cors.allowed(origin) {
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if hub.cors.anyOrigin && !hub.cors.cfg.AllowCredentials {
+				w.Header().Set(allowOriginHeader, anyOrigin)
+			} else {
+				w.Header().Set(allowOriginHeader, origin)
+			}
+			if hub.cors.cfg.AllowCredentials {
+				w.Header().Set(allowCredentialsHeader, "true")
+			}
+
+			if !preflight {
+				if hub.cors.exposed != "" {
+					w.Header().Set(exposeHeadersHeader, hub.cors.exposed)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add(varyHeader, requestMethodHeader)
+			w.Header().Add(varyHeader, requestHeadersHeader)
+			w.Header().Set(allowMethodsHeader, hub.cors.methods)
+			if hub.cors.headers != "" {
+				w.Header().Set(allowHeadersHeader, hub.cors.headers)
+			}
+			if hub.cors.cfg.MaxAge > 0 {
+				w.Header().Set(maxAgeHeader, hub.cors.maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
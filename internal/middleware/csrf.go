@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/controller/apierrors"
+	"auth-service/internal/controller/responser"
+	"auth-service/internal/mappers/dtomap"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	originHeader  = "Origin"
+	refererHeader = "Referer"
+)
+
+var (
+	errUntrustedOrigin = errors.New("untrusted origin")
+	errCSRFToken       = errors.New("csrf token mismatch")
+)
+
+// csrfGuard checks the signed double-submit token and the request origin.
+type csrfGuard struct {
+	cfg     config.CSRF
+	origins map[string]struct{}
+}
+
+func newCSRFGuard(cfg config.CSRF) *csrfGuard {
+	origins := make(map[string]struct{}, len(cfg.TrustedOrigins))
+	for _, origin := range cfg.TrustedOrigins {
+		origins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = struct{}{}
+	}
+
+	return &csrfGuard{
+		cfg:     cfg,
+		origins: origins,
+	}
+}
+
+// token binds the CSRF token to the refresh token, so it can't be planted without the refresh cookie.
+func (g *csrfGuard) token(refreshToken string) string {
+	mac := hmac.New(sha256.New, []byte(g.cfg.Secret))
+	mac.Write([]byte("csrf:" + refreshToken))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (g *csrfGuard) verify(r *http.Request, refreshCookieName string) error {
+	if err := g.verifyOrigin(r); err != nil {
+		return err
+	}
+
+	// Without the refresh cookie there is no ambient credential to forge a request with.
+	refreshCookie, err := r.Cookie(refreshCookieName)
+	if err != nil {
+		return nil
+	}
+
+	expected := g.token(refreshCookie.Value)
+	if !hmac.Equal([]byte(r.Header.Get(g.cfg.HeaderName)), []byte(expected)) {
+		return errCSRFToken
+	}
+
+	return nil
+}
+
+// verifyOrigin accepts same-origin and trusted origins, taken from Origin or else Referer.
+// Requests carrying neither come from non-browser clients and are left to the token check.
+func (g *csrfGuard) verifyOrigin(r *http.Request) error {
+	source := r.Header.Get(originHeader)
+	if source == "" {
+		source = r.Header.Get(refererHeader)
+	}
+	if source == "" {
+		return nil
+	}
+
+	sourceURL, err := url.Parse(source)
+	if err != nil || sourceURL.Host == "" {
+		return fmt.Errorf("%w: %q", errUntrustedOrigin, source)
+	}
+
+	if strings.EqualFold(sourceURL.Host, r.Host) {
+		return nil
+	}
+
+	origin := strings.ToLower(sourceURL.Scheme + "://" + sourceURL.Host)
+	if _, ok := g.origins[origin]; !ok {
+		return fmt.Errorf("%w: %q", errUntrustedOrigin, origin)
+	}
+
+	return nil
+}
+
+// CSRF guards routes consuming the refresh cookie. It passes everything through when CSRF protection is disabled.
+func (hub *middlewareHub) CSRF() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if hub.csrf == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := hub.csrf.verify(r, hub.tokenizer.RefreshCookieName())
+			if err != nil {
+				responser.MakeErrorResponseJSON(w, dtomap.MapToErrorResponse(
+					fmt.Errorf("%w: %w", apierrors.ErrForgeryCheckFailed, err), http.StatusForbidden))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SetCSRFToken issues the CSRF token for the refresh cookie in a cookie readable by scripts, sharing its lifetime,
+// and in the response header the token is expected back in, for front-ends on other sites that can't read the cookie.
+// It does nothing when CSRF protection is disabled.
+func (hub *middlewareHub) SetCSRFToken(w http.ResponseWriter, refreshCookie *http.Cookie) {
+	if hub.csrf == nil {
+		return
+	}
+
+	cookie := *refreshCookie
+	cookie.Name = hub.csrf.cfg.CookieName
+	if strings.HasPrefix(refreshCookie.Name, config.HostCookiePrefix) {
+		cookie.Name = config.HostCookiePrefix + cookie.Name
+	}
+	cookie.Path = "/"
+	cookie.HttpOnly = false
+	if refreshCookie.MaxAge >= 0 {
+		cookie.Value = hub.csrf.token(refreshCookie.Value)
+		w.Header().Set(hub.csrf.cfg.HeaderName, cookie.Value)
+	}
+
+	http.SetCookie(w, &cookie)
+}
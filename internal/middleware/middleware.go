@@ -1,38 +1,78 @@
 package middleware
 
 import (
+	"auth-service/internal/config"
 	"auth-service/internal/controller/apierrors"
 	"auth-service/internal/controller/responser"
 	"auth-service/internal/mappers/dtomap"
+	"auth-service/internal/revocation"
 	"auth-service/internal/tokenizer"
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 const AuthorizationHeader = "Authorization"
 
+var (
+	errSessionRevoked    = errors.New("session revoked")
+	errIssuedBeforeEpoch = errors.New("token issued before the not-before epoch")
+)
+
 const (
 	UserGUIDKey contextKey = iota
+	SessionIDKey
+	ClientIDKey
 )
 
 type contextKey int8
 
 type Middleware interface {
 	Auth() mux.MiddlewareFunc
+	CSRF() mux.MiddlewareFunc
+	SetCSRFToken(w http.ResponseWriter, refreshCookie *http.Cookie)
+	CORS() mux.MiddlewareFunc
+	Admin() mux.MiddlewareFunc
+	Client() mux.MiddlewareFunc
 }
 
 type middlewareHub struct {
-	tokenizer tokenizer.Tokenizer
+	tokenizer     tokenizer.Tokenizer
+	revoked       revocation.Checker
+	csrf          *csrfGuard
+	cors          *corsPolicy
+	adminToken    string
+	clientSecrets map[string]string
 }
 
-func New(tok tokenizer.Tokenizer) Middleware {
-	return &middlewareHub{
-		tokenizer: tok,
+func New(tok tokenizer.Tokenizer, revoked revocation.Checker, csrfCfg config.CSRF, corsCfg config.CORS,
+	adminCfg config.Admin, clientSecrets map[string]string) Middleware {
+	hub := &middlewareHub{
+		tokenizer:     tok,
+		revoked:       revoked,
+		adminToken:    adminCfg.Token,
+		clientSecrets: clientSecrets,
+	}
+	if csrfCfg.Enabled {
+		hub.csrf = newCSRFGuard(csrfCfg)
+		// Cross-origin front-ends read the CSRF token from the response header.
+		if !slices.ContainsFunc(corsCfg.ExposedHeaders, func(header string) bool {
+			return strings.EqualFold(header, csrfCfg.HeaderName)
+		}) {
+			corsCfg.ExposedHeaders = append(slices.Clone(corsCfg.ExposedHeaders), csrfCfg.HeaderName)
+		}
+	}
+	if len(corsCfg.AllowedOrigins) > 0 {
+		hub.cors = newCORSPolicy(corsCfg)
 	}
+
+	return hub
 }
 
 func (hub *middlewareHub) Auth() mux.MiddlewareFunc {
@@ -53,7 +93,54 @@ func (hub *middlewareHub) Auth() mux.MiddlewareFunc {
 				return
 			}
 
-			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), UserGUIDKey, userGUID)))
+			sessionID, ok := claims[tokenizer.SessionClaimsKey].(string)
+			if !ok {
+				responser.MakeErrorResponseJSON(w, dtomap.MapToErrorResponse(apierrors.ErrAuthenticationFailed, http.StatusForbidden))
+				return
+			}
+
+			issuedAt, err := claims.GetIssuedAt()
+			if err != nil || issuedAt == nil {
+				responser.MakeErrorResponseJSON(w, dtomap.MapToErrorResponse(apierrors.ErrAuthenticationFailed, http.StatusForbidden))
+				return
+			}
+
+			revokedErr, err := hub.checkRevoked(r.Context(), userGUID, sessionID, issuedAt.Time)
+			if err != nil {
+				responser.MakeErrorResponseJSON(w, dtomap.MapToErrorResponse(apierrors.ErrSomethingWentWrong, http.StatusInternalServerError))
+				return
+			}
+			if revokedErr != nil {
+				responser.MakeErrorResponseJSON(w, dtomap.MapToErrorResponse(
+					fmt.Errorf("%w: %w", apierrors.ErrAuthenticationFailed, revokedErr), http.StatusForbidden))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), UserGUIDKey, userGUID)
+			ctx = context.WithValue(ctx, SessionIDKey, sessionID)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// checkRevoked returns the reason the token was revoked, if it was, or the error of the check itself.
+func (hub *middlewareHub) checkRevoked(ctx context.Context, userGUID, sessionID string, issuedAt time.Time) (error, error) {
+	notBefore, err := hub.revoked.NotBefore(ctx, userGUID)
+	if err != nil {
+		return nil, err
+	}
+	if revocation.IssuedBefore(issuedAt, notBefore) {
+		return errIssuedBeforeEpoch, nil
+	}
+
+	active, err := hub.revoked.Active(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return errSessionRevoked, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,9 @@
+package notifier
+
+import "errors"
+
+// Notifier errors.
+var (
+	ErrSMTPDelivery    = errors.New("notifier: smtp delivery failed")
+	ErrWebhookDelivery = errors.New("notifier: webhook delivery failed")
+)
@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/types/models"
+	"context"
+	"errors"
+)
+
+type Notifier interface {
+	Notify(ctx context.Context, event *models.SecurityEvent) error
+}
+
+// New builds a notifier fanning events out to every configured sender.
+// With no senders configured events are silently dropped.
+func New(cfg config.Notifier) Notifier {
+	var senders []Notifier
+
+	if cfg.SMTP.Host != "" {
+		senders = append(senders, NewSMTP(cfg.SMTP, cfg.Timeout))
+	}
+	if cfg.Webhook.URL != "" {
+		senders = append(senders, NewWebhook(cfg.Webhook, cfg.Timeout))
+	}
+
+	return &multiNotifier{
+		senders: senders,
+	}
+}
+
+type multiNotifier struct {
+	senders []Notifier
+}
+
+func (n *multiNotifier) Notify(ctx context.Context, event *models.SecurityEvent) error {
+	var errs []error
+	for _, sender := range n.senders {
+		if err := sender.Notify(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
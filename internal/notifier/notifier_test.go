@@ -0,0 +1,19 @@
+package notifier
+
+import (
+	"auth-service/internal/types/models"
+	"net/netip"
+	"time"
+)
+
+func testEvent() *models.SecurityEvent {
+	return &models.SecurityEvent{
+		Type:       models.SecurityEventIPChanged,
+		UserGUID:   "eb8a32db-139f-4e33-b172-39810efcc487",
+		SessionID:  "0f8d2f5e-7c1a-4b7e-9a53-2f0c6f1d9b11",
+		UserAgent:  "test-agent",
+		PreviousIP: netip.MustParseAddr("192.0.2.1"),
+		IP:         netip.MustParseAddr("198.51.100.7"),
+		OccurredAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
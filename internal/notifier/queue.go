@@ -0,0 +1,88 @@
+package notifier
+
+import (
+	"auth-service/internal/metrics"
+	"auth-service/internal/types/models"
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Dispatcher delivers security events in the background, off the request path.
+type Dispatcher interface {
+	// Dispatch queues the event, dropping it when the queue is full.
+	Dispatch(event *models.SecurityEvent)
+	// Close stops accepting events and waits until the queued ones are delivered, at most until ctx is done.
+	Close(ctx context.Context) error
+}
+
+type queuedDispatcher struct {
+	next    Notifier
+	events  chan *models.SecurityEvent
+	metrics *metrics.Metrics
+	logger  *slog.Logger
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// NewQueue delivers events through next on a single worker behind a queue of the given size,
+// so a slow or unreachable sender never piles up goroutines.
+func NewQueue(next Notifier, size int, m *metrics.Metrics, logger *slog.Logger) Dispatcher {
+	d := &queuedDispatcher{
+		next:    next,
+		events:  make(chan *models.SecurityEvent, size),
+		metrics: m,
+		logger:  logger,
+		done:    make(chan struct{}),
+	}
+
+	go d.worker()
+	return d
+}
+
+func (d *queuedDispatcher) worker() {
+	defer close(d.done)
+	for event := range d.events {
+		err := d.next.Notify(context.Background(), event)
+		if err != nil {
+			d.metrics.ObserveNotification(metrics.OutcomeError)
+			d.logger.Error(err.Error())
+			continue
+		}
+		d.metrics.ObserveNotification(metrics.OutcomeOK)
+	}
+}
+
+func (d *queuedDispatcher) Dispatch(event *models.SecurityEvent) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if !d.closed {
+		select {
+		case d.events <- event:
+			return
+		default:
+		}
+	}
+
+	d.metrics.ObserveNotification(metrics.OutcomeDropped)
+	d.logger.Warn("security event dropped", slog.String("type", event.Type), slog.String("session_id", event.SessionID))
+}
+
+func (d *queuedDispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.events)
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
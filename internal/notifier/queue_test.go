@@ -0,0 +1,53 @@
+package notifier
+
+import (
+	"auth-service/internal/metrics"
+	"auth-service/internal/types/models"
+	"context"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type blockingNotifier struct {
+	release   chan struct{}
+	delivered atomic.Int64
+}
+
+func (n *blockingNotifier) Notify(ctx context.Context, event *models.SecurityEvent) error {
+	<-n.release
+	n.delivered.Add(1)
+	return nil
+}
+
+func TestQueueDropsWhenFullAndDrainsOnClose(t *testing.T) {
+	next := &blockingNotifier{release: make(chan struct{})}
+	queue := NewQueue(next, 1, metrics.New(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// The worker holds the first event, the second fills the queue and the third is dropped.
+	for range 3 {
+		queue.Dispatch(testEvent())
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := queue.Close(ctx); err == nil {
+		t.Fatal("close returned before the queue was drained")
+	}
+
+	close(next.release)
+	if err := queue.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := next.delivered.Load(); got != 2 {
+		t.Errorf("delivered %d events, want 2", got)
+	}
+
+	queue.Dispatch(testEvent())
+	if got := next.delivered.Load(); got != 2 {
+		t.Errorf("event delivered after close")
+	}
+}
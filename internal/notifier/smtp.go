@@ -0,0 +1,125 @@
+package notifier
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/types/models"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type smtpNotifier struct {
+	addr    string
+	host    string
+	auth    smtp.Auth
+	from    string
+	to      []string
+	timeout time.Duration
+}
+
+func NewSMTP(cfg config.SMTP, timeout time.Duration) Notifier {
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return &smtpNotifier{
+		addr:    net.JoinHostPort(cfg.Host, cfg.Port),
+		host:    cfg.Host,
+		auth:    auth,
+		from:    cfg.From,
+		to:      cfg.To,
+		timeout: timeout,
+	}
+}
+
+func (n *smtpNotifier) Notify(ctx context.Context, event *models.SecurityEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	err := n.send(ctx, buildMessage(n.from, n.to, event))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSMTPDelivery, err)
+	}
+
+	return nil
+}
+
+func (n *smtpNotifier) send(ctx context.Context, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return err
+		}
+	}
+
+	if n.auth != nil {
+		if err := client.Auth(n.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.from); err != nil {
+		return err
+	}
+	for _, rcpt := range n.to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func buildMessage(from string, to []string, event *models.SecurityEvent) []byte {
+	var msg bytes.Buffer
+
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: Security alert: %s\r\n", event.Type)
+	fmt.Fprintf(&msg, "Date: %s\r\n", event.OccurredAt.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+
+	fmt.Fprintf(&msg, "Event:       %s\r\n", event.Type)
+	fmt.Fprintf(&msg, "User:        %s\r\n", event.UserGUID)
+	fmt.Fprintf(&msg, "Session:     %s\r\n", event.SessionID)
+	fmt.Fprintf(&msg, "User agent:  %s\r\n", event.UserAgent)
+	fmt.Fprintf(&msg, "Previous IP: %s\r\n", event.PreviousIP)
+	fmt.Fprintf(&msg, "New IP:      %s\r\n", event.IP)
+	fmt.Fprintf(&msg, "Time:        %s\r\n", event.OccurredAt.UTC().Format(time.RFC3339))
+
+	return msg.Bytes()
+}
@@ -0,0 +1,172 @@
+package notifier
+
+import (
+	"auth-service/internal/config"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is a minimal SMTP server accepting one message per connection, without TLS or auth.
+type fakeSMTP struct {
+	listener net.Listener
+	// rejectRcpt makes the server refuse every recipient.
+	rejectRcpt bool
+	messages   chan fakeMessage
+}
+
+type fakeMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTP(t *testing.T, rejectRcpt bool) *fakeSMTP {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeSMTP{
+		listener:   listener,
+		rejectRcpt: rejectRcpt,
+		messages:   make(chan fakeMessage, 1),
+	}
+	go server.serve()
+
+	return server
+}
+
+func (s *fakeSMTP) config() config.SMTP {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return config.SMTP{
+		Host: host,
+		Port: port,
+		From: "auth@example.com",
+		To:   []string{"security@example.com", "oncall@example.com"},
+	}
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	var msg fakeMessage
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250 fake")
+		case "MAIL":
+			msg.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			reply("250 ok")
+		case "RCPT":
+			if s.rejectRcpt {
+				reply("550 no such user")
+				continue
+			}
+			msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			msg.data = data.String()
+			reply("250 queued")
+			s.messages <- msg
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestSMTPNotify(t *testing.T) {
+	server := newFakeSMTP(t, false)
+	cfg := server.config()
+
+	sender := NewSMTP(cfg, time.Second)
+	if err := sender.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	msg := <-server.messages
+	if msg.from != cfg.From {
+		t.Errorf("from = %q, want %q", msg.from, cfg.From)
+	}
+	if strings.Join(msg.to, ",") != strings.Join(cfg.To, ",") {
+		t.Errorf("to = %v, want %v", msg.to, cfg.To)
+	}
+
+	event := testEvent()
+	for _, want := range []string{
+		"Subject: Security alert: " + event.Type,
+		"Session:     " + event.SessionID,
+		"Previous IP: " + event.PreviousIP.String(),
+		"New IP:      " + event.IP.String(),
+	} {
+		if !strings.Contains(msg.data, want) {
+			t.Errorf("message lacks %q:\n%s", want, msg.data)
+		}
+	}
+}
+
+func TestSMTPNotifyRejected(t *testing.T) {
+	server := newFakeSMTP(t, true)
+
+	sender := NewSMTP(server.config(), time.Second)
+	err := sender.Notify(context.Background(), testEvent())
+	if !errors.Is(err, ErrSMTPDelivery) {
+		t.Fatalf("err = %v, want %v", err, ErrSMTPDelivery)
+	}
+}
+
+func TestSMTPNotifyUnreachable(t *testing.T) {
+	server := newFakeSMTP(t, false)
+	cfg := server.config()
+	server.listener.Close()
+
+	sender := NewSMTP(cfg, time.Second)
+	err := sender.Notify(context.Background(), testEvent())
+	if !errors.Is(err, ErrSMTPDelivery) {
+		t.Fatalf("err = %v, want %v", err, ErrSMTPDelivery)
+	}
+}
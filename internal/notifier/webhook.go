@@ -0,0 +1,64 @@
+package notifier
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/mappers/dtomap"
+	"auth-service/internal/types/models"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body when a webhook secret is configured.
+const SignatureHeader = "X-Auth-Signature"
+
+type webhookNotifier struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func NewWebhook(cfg config.Webhook, timeout time.Duration) Notifier {
+	return &webhookNotifier{
+		url:    cfg.URL,
+		secret: []byte(cfg.Secret),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, event *models.SecurityEvent) error {
+	body, err := json.Marshal(dtomap.MapToSecurityEventPayload(event))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWebhookDelivery, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWebhookDelivery, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if len(n.secret) > 0 {
+		mac := hmac.New(sha256.New, n.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrWebhookDelivery, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: unexpected status %s", ErrWebhookDelivery, resp.Status)
+	}
+
+	return nil
+}
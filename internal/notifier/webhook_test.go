@@ -0,0 +1,95 @@
+package notifier
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/types/dto"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	const secret = "webhook-secret"
+
+	var (
+		body      []byte
+		signature string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewWebhook(config.Webhook{URL: server.URL, Secret: secret}, time.Second)
+	if err := sender.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+
+	var payload dto.SecurityEventPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	event := testEvent()
+	if payload.Type != event.Type || payload.SessionID != event.SessionID ||
+		payload.PreviousIP != event.PreviousIP.String() || payload.IP != event.IP.String() {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Errorf("signature = %q, want %q", signature, want)
+	}
+}
+
+func TestWebhookNotifyUnsigned(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(SignatureHeader) != "" {
+			t.Error("signature sent without a secret")
+		}
+	}))
+	defer server.Close()
+
+	sender := NewWebhook(config.Webhook{URL: server.URL}, time.Second)
+	if err := sender.Notify(context.Background(), testEvent()); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+}
+
+func TestWebhookNotifyRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sender := NewWebhook(config.Webhook{URL: server.URL}, time.Second)
+	err := sender.Notify(context.Background(), testEvent())
+	if !errors.Is(err, ErrWebhookDelivery) {
+		t.Fatalf("err = %v, want %v", err, ErrWebhookDelivery)
+	}
+}
+
+func TestWebhookNotifyTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	sender := NewWebhook(config.Webhook{URL: server.URL}, 50*time.Millisecond)
+	err := sender.Notify(context.Background(), testEvent())
+	if !errors.Is(err, ErrWebhookDelivery) {
+		t.Fatalf("err = %v, want %v", err, ErrWebhookDelivery)
+	}
+}
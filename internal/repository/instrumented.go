@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"auth-service/internal/metrics"
+	"auth-service/internal/types/models"
+	"auth-service/internal/types/queries"
+	"context"
+	"time"
+)
+
+// instrumentedRepository records the latency and outcome of every query of the wrapped repository.
+type instrumentedRepository struct {
+	next    Repository
+	metrics *metrics.Metrics
+}
+
+func NewInstrumented(next Repository, m *metrics.Metrics) Repository {
+	return &instrumentedRepository{
+		next:    next,
+		metrics: m,
+	}
+}
+
+func (r *instrumentedRepository) GetSession(ctx context.Context, getSession *queries.GetSessionQuery) (*models.Session, error) {
+	start := time.Now()
+	result, err := r.next.GetSession(ctx, getSession)
+	r.metrics.ObserveQuery("get_session", time.Since(start), err)
+	return result, err
+}
+
+func (r *instrumentedRepository) CreateSession(ctx context.Context, createSession *queries.CreateSessionQuery) error {
+	start := time.Now()
+	err := r.next.CreateSession(ctx, createSession)
+	r.metrics.ObserveQuery("create_session", time.Since(start), err)
+	return err
+}
+
+func (r *instrumentedRepository) DeleteSession(ctx context.Context, sessionID string) error {
+	start := time.Now()
+	err := r.next.DeleteSession(ctx, sessionID)
+	r.metrics.ObserveQuery("delete_session", time.Since(start), err)
+	return err
+}
+
+func (r *instrumentedRepository) RenewSession(ctx context.Context, renewSession *queries.RenewSessionQuery) error {
+	start := time.Now()
+	err := r.next.RenewSession(ctx, renewSession)
+	r.metrics.ObserveQuery("renew_session", time.Since(start), err)
+	return err
+}
+
+func (r *instrumentedRepository) GetConsumedRefreshToken(ctx context.Context, tokenDigest string) (*models.ConsumedRefreshToken, error) {
+	start := time.Now()
+	result, err := r.next.GetConsumedRefreshToken(ctx, tokenDigest)
+	r.metrics.ObserveQuery("get_consumed_refresh_token", time.Since(start), err)
+	return result, err
+}
+
+func (r *instrumentedRepository) ListUserSessions(ctx context.Context, userGUID string) ([]*models.Session, error) {
+	start := time.Now()
+	result, err := r.next.ListUserSessions(ctx, userGUID)
+	r.metrics.ObserveQuery("list_user_sessions", time.Since(start), err)
+	return result, err
+}
+
+func (r *instrumentedRepository) DeleteUserSession(ctx context.Context, deleteUserSession *queries.DeleteUserSessionQuery) error {
+	start := time.Now()
+	err := r.next.DeleteUserSession(ctx, deleteUserSession)
+	r.metrics.ObserveQuery("delete_user_session", time.Since(start), err)
+	return err
+}
+
+func (r *instrumentedRepository) DeleteOtherUserSessions(ctx context.Context, deleteOtherUserSessions *queries.DeleteOtherUserSessionsQuery) error {
+	start := time.Now()
+	err := r.next.DeleteOtherUserSessions(ctx, deleteOtherUserSessions)
+	r.metrics.ObserveQuery("delete_other_user_sessions", time.Since(start), err)
+	return err
+}
+
+func (r *instrumentedRepository) CountActiveSessions(ctx context.Context) (int, error) {
+	start := time.Now()
+	result, err := r.next.CountActiveSessions(ctx)
+	r.metrics.ObserveQuery("count_active_sessions", time.Since(start), err)
+	return result, err
+}
+
+func (r *instrumentedRepository) DeleteExpiredSessions(ctx context.Context, deleteExpiredSessions *queries.DeleteExpiredSessionsQuery) (int64, bool, error) {
+	start := time.Now()
+	deleted, locked, err := r.next.DeleteExpiredSessions(ctx, deleteExpiredSessions)
+	r.metrics.ObserveQuery("delete_expired_sessions", time.Since(start), err)
+	return deleted, locked, err
+}
+
+func (r *instrumentedRepository) ListSigningKeys(ctx context.Context) ([]*models.SigningKey, error) {
+	start := time.Now()
+	result, err := r.next.ListSigningKeys(ctx)
+	r.metrics.ObserveQuery("list_signing_keys", time.Since(start), err)
+	return result, err
+}
+
+func (r *instrumentedRepository) CreateSigningKey(ctx context.Context, createSigningKey *queries.CreateSigningKeyQuery) error {
+	start := time.Now()
+	err := r.next.CreateSigningKey(ctx, createSigningKey)
+	r.metrics.ObserveQuery("create_signing_key", time.Since(start), err)
+	return err
+}
+
+func (r *instrumentedRepository) PromoteSigningKey(ctx context.Context, promoteSigningKey *queries.PromoteSigningKeyQuery) error {
+	start := time.Now()
+	err := r.next.PromoteSigningKey(ctx, promoteSigningKey)
+	r.metrics.ObserveQuery("promote_signing_key", time.Since(start), err)
+	return err
+}
+
+func (r *instrumentedRepository) RetireSigningKey(ctx context.Context, keyID string) error {
+	start := time.Now()
+	err := r.next.RetireSigningKey(ctx, keyID)
+	r.metrics.ObserveQuery("retire_signing_key", time.Since(start), err)
+	return err
+}
+
+func (r *instrumentedRepository) DeleteExpiredSigningKeys(ctx context.Context) error {
+	start := time.Now()
+	err := r.next.DeleteExpiredSigningKeys(ctx)
+	r.metrics.ObserveQuery("delete_expired_signing_keys", time.Since(start), err)
+	return err
+}
+
+func (r *instrumentedRepository) GetNotBefore(ctx context.Context, userGUID string) (time.Time, error) {
+	start := time.Now()
+	result, err := r.next.GetNotBefore(ctx, userGUID)
+	r.metrics.ObserveQuery("get_not_before", time.Since(start), err)
+	return result, err
+}
+
+func (r *instrumentedRepository) BumpNotBefore(ctx context.Context, bumpNotBefore *queries.BumpNotBeforeQuery) (time.Time, error) {
+	start := time.Now()
+	result, err := r.next.BumpNotBefore(ctx, bumpNotBefore)
+	r.metrics.ObserveQuery("bump_not_before", time.Since(start), err)
+	return result, err
+}
+
+func (r *instrumentedRepository) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := r.next.Ping(ctx)
+	r.metrics.ObserveQuery("ping", time.Since(start), err)
+	return err
+}
+
+func (r *instrumentedRepository) SchemaVersion(ctx context.Context) (*models.SchemaVersion, error) {
+	start := time.Now()
+	result, err := r.next.SchemaVersion(ctx)
+	r.metrics.ObserveQuery("schema_version", time.Since(start), err)
+	return result, err
+}
+
+func (r *instrumentedRepository) Close() error {
+	return r.next.Close()
+}
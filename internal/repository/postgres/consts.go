@@ -11,4 +11,37 @@ const (
 	PairIDColumn       = "pair_id"
 	ExpiresAtColumn    = "expires_at"
 	CreatedAtColumn    = "created_at"
+	RefreshedAtColumn  = "refreshed_at"
+
+	ConsumedRefreshTokensTable = "consumed_refresh_tokens"
+
+	SessionIDRefColumn = "session_id"
+	ConsumedAtColumn   = "consumed_at"
+	SuccessorColumn    = "successor"
+	TokenDigestColumn  = "token_digest"
+
+	SigningKeysTable = "signing_keys"
+
+	KeyIDColumn       = "kid"
+	AlgorithmColumn   = "algorithm"
+	PrivateKeyColumn  = "private_key"
+	ActiveColumn      = "active"
+	ActivatedAtColumn = "activated_at"
+	VerifyUntilColumn = "verify_until"
+
+	NotBeforeEpochsTable = "not_before_epochs"
+
+	ScopeColumn     = "scope"
+	NotBeforeColumn = "not_before"
+
+	// GlobalScope is the scope of the epoch applying to every user.
+	GlobalScope = "*"
+
+	SchemaMigrationsTable = "schema_migrations"
+
+	// SessionSweepLock is the advisory lock key taken by the replica sweeping expired sessions.
+	SessionSweepLock int64 = 0x61757468_73776570
+
+	VersionColumn = "version"
+	DirtyColumn   = "dirty"
 )
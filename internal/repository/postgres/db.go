@@ -9,71 +9,107 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
-	"os"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 
 	_ "github.com/lib/pq"
 )
 
 type postgresDB struct {
-	db *sql.DB
+	db *tracedDB
+	// schemaVersion is the latest migration version, the one the schema is expected at.
+	schemaVersion uint
 }
 
+// New connects to the database and migrates it up, unless migrations are skipped by the config.
 func New(cfg config.DBConn) (*postgresDB, error) {
-	database, err := sql.Open("postgres", cfg.URL)
+	s, err := connect(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	err = database.Ping()
+	if !cfg.SkipMigrations {
+		m, err := newMigrate(s.db.DB)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+
+		if err := ignoreNoChange(m.Up()); err != nil {
+			s.Close()
+			return nil, err
+		}
+
+		slog.Info("database migrated")
+	}
+
+	return s, nil
+}
+
+// Open connects to the database without touching its schema,
+// refusing to work with a schema that is not at the latest migration.
+func Open(ctx context.Context, cfg config.DBConn) (*postgresDB, error) {
+	s, err := connect(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	database.SetMaxOpenConns(cfg.MaxOpenConns)
+	version, err := s.SchemaVersion(ctx)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
 
-	migrationsDir := os.Getenv("MIGRATIONS_DIR")
+	if version.Dirty || version.Current != version.Expected {
+		s.Close()
+		return nil, fmt.Errorf("%w: at %d (dirty: %t), expected %d",
+			repoerrors.ErrSchemaMismatch, version.Current, version.Dirty, version.Expected)
+	}
 
-	driver, err := postgres.WithInstance(database, &postgres.Config{})
+	return s, nil
+}
+
+func connect(cfg config.DBConn) (*postgresDB, error) {
+	database, err := sql.Open("postgres", cfg.URL)
 	if err != nil {
 		return nil, err
 	}
 
-	migrator, err := migrate.NewWithDatabaseInstance(migrationsDir, "auth-db", driver)
+	err = database.Ping()
 	if err != nil {
+		database.Close()
 		return nil, err
 	}
 
-	if err := migrator.Up(); err != nil && err != migrate.ErrNoChange {
+	database.SetMaxOpenConns(cfg.MaxOpenConns)
+
+	schemaVersion, err := latestMigration()
+	if err != nil {
+		database.Close()
 		return nil, err
 	}
 
-	slog.Info("database migrated and ready")
-
 	return &postgresDB{
-		db: database,
+		db:            &tracedDB{DB: database},
+		schemaVersion: schemaVersion,
 	}, nil
 }
 
+func (s *postgresDB) Close() error {
+	return s.db.Close()
+}
+
 func (s *postgresDB) GetSession(ctx context.Context, getSessionQuery *queries.GetSessionQuery) (*models.Session, error) {
-	query, args, err := sq.Select("*").
+	query, args, err := sq.Select(sessionColumns...).
 		From(SessionsTable).
-		Where(sq.Eq{UserIDColumn: getSessionQuery.UserGUID, UserAgentColumn: getSessionQuery.UserAgent}).
+		Where(sq.Eq{SessionIDColumn: getSessionQuery.SessionID}).
 		PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
 	}
 
-	row := s.db.QueryRowContext(ctx, query, args...)
-
-	var session models.Session
-
-	err = row.Scan(&session.ID, &session.UserGUID, &session.RefreshToken,
-		&session.UserAgent, &session.IP, &session.PairID, &session.ExpiresAt, &session.CreatedAt)
+	session, err := scanSession(s.db.QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -81,15 +117,40 @@ func (s *postgresDB) GetSession(ctx context.Context, getSessionQuery *queries.Ge
 		return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
 	}
 
-	return &session, nil
+	return session, nil
 }
 
-func (s *postgresDB) CreateSession(ctx context.Context, createSessionquery *queries.CreateSessionQuery) error {
-	err := createSession(ctx, s.db, createSessionquery)
+// CreateSession stores the session, dropping the user's expired sessions
+// and evicting the oldest ones beyond the per user limit.
+func (s *postgresDB) CreateSession(ctx context.Context, createSessionQuery *queries.CreateSessionQuery) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrTransactionBegin, err)
+	}
+
+	err = evictUserSessions(ctx, tx, createSessionQuery.UserGUID, createSessionQuery.MaxUserSessions)
+	if err != nil {
+		txErr := tx.Rollback()
+		if txErr != nil {
+			return fmt.Errorf("%w + %w", err, txErr)
+		}
+		return err
+	}
+
+	err = createSession(ctx, tx, createSessionQuery)
+	if err != nil {
+		txErr := tx.Rollback()
+		if txErr != nil {
+			return fmt.Errorf("%w + %w", err, txErr)
+		}
 		return err
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrTransactionCommit, err)
+	}
+
 	return nil
 }
 
@@ -102,13 +163,16 @@ func (s *postgresDB) DeleteSession(ctx context.Context, sessionID string) error
 	return nil
 }
 
-func (s *postgresDB) RenewSession(ctx context.Context, oldSessionID string, createSessionQuery *queries.CreateSessionQuery) error {
+// RenewSession rotates the session's token pair in place, keeping its ID,
+// and records the consumed pair in the same transaction. The update is a compare-and-swap
+// on the consumed pair ID: repoerrors.ErrNotFound means a concurrent renewal won.
+func (s *postgresDB) RenewSession(ctx context.Context, renewSessionQuery *queries.RenewSessionQuery) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("%w: %w", repoerrors.ErrTransactionBegin, err)
 	}
 
-	err = deleteSession(ctx, tx, oldSessionID)
+	err = renewSession(ctx, tx, renewSessionQuery)
 	if err != nil {
 		txErr := tx.Rollback()
 		if txErr != nil {
@@ -117,19 +181,113 @@ func (s *postgresDB) RenewSession(ctx context.Context, oldSessionID string, crea
 		return err
 	}
 
-	err = createSession(ctx, tx, createSessionQuery)
+	err = tx.Commit()
 	if err != nil {
-		txErr := tx.Rollback()
-		if txErr != nil {
-			return fmt.Errorf("%w + %w", err, txErr)
+		return fmt.Errorf("%w: %w", repoerrors.ErrTransactionCommit, err)
+	}
+
+	return nil
+}
+
+func (s *postgresDB) GetConsumedRefreshToken(ctx context.Context, tokenDigest string) (*models.ConsumedRefreshToken, error) {
+	query, args, err := sq.Select(TokenDigestColumn, SessionIDRefColumn, ConsumedAtColumn, SuccessorColumn).
+		From(ConsumedRefreshTokensTable).
+		Where(sq.Eq{TokenDigestColumn: tokenDigest}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	var consumed models.ConsumedRefreshToken
+
+	err = s.db.QueryRowContext(ctx, query, args...).
+		Scan(&consumed.TokenDigest, &consumed.SessionID, &consumed.ConsumedAt, &consumed.Successor)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
 		}
-		return err
+		return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
 	}
 
-	err = tx.Commit()
+	return &consumed, nil
+}
+
+// ListUserSessions returns the user's unexpired sessions, newest first.
+func (s *postgresDB) ListUserSessions(ctx context.Context, userGUID string) ([]*models.Session, error) {
+	query, args, err := sq.Select(sessionColumns...).
+		From(SessionsTable).
+		Where(sq.Eq{UserIDColumn: userGUID}).
+		Where(sq.Gt{ExpiresAtColumn: time.Now()}).
+		OrderBy(CreatedAtColumn + " DESC").
+		PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
-		return fmt.Errorf("%w: %w", repoerrors.ErrTransactionCommit, err)
+		return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+	defer rows.Close()
+
+	var sessions []*models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+
+	return sessions, nil
+}
+
+func (s *postgresDB) DeleteUserSession(ctx context.Context, deleteUserSessionQuery *queries.DeleteUserSessionQuery) error {
+	query, args, err := sq.Delete(SessionsTable).
+		Where(sq.Eq{SessionIDColumn: deleteUserSessionQuery.SessionID, UserIDColumn: deleteUserSessionQuery.UserGUID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	return execAffectingRows(ctx, s.db, query, args...)
+}
+
+func (s *postgresDB) DeleteOtherUserSessions(ctx context.Context, deleteOtherUserSessionsQuery *queries.DeleteOtherUserSessionsQuery) error {
+	query, args, err := sq.Delete(SessionsTable).
+		Where(sq.Eq{UserIDColumn: deleteOtherUserSessionsQuery.UserGUID}).
+		Where(sq.NotEq{SessionIDColumn: deleteOtherUserSessionsQuery.KeepSessionID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	_, err = s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
 	}
 
 	return nil
 }
+
+// CountActiveSessions counts the sessions not expired yet.
+func (s *postgresDB) CountActiveSessions(ctx context.Context) (int, error) {
+	query, args, err := sq.Select("COUNT(*)").
+		From(SessionsTable).
+		Where(sq.Gt{ExpiresAtColumn: time.Now()}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	var count int
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+
+	return count, nil
+}
@@ -2,10 +2,14 @@ package postgres
 
 import (
 	"auth-service/internal/repository/repoerrors"
+	"auth-service/internal/types/models"
 	"auth-service/internal/types/queries"
 	"context"
 	"database/sql"
 	"fmt"
+	"net/netip"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 )
@@ -18,10 +22,10 @@ type sqlexecutor interface {
 
 func createSession(ctx context.Context, exec sqlexecutor, createSessionQuery *queries.CreateSessionQuery) error {
 	query, args, err := sq.Insert(SessionsTable).
-		Columns(UserIDColumn, RefreshTokenColumn, UserAgentColumn, IPColumn, PairIDColumn, ExpiresAtColumn).
+		Columns(SessionIDColumn, UserIDColumn, RefreshTokenColumn, UserAgentColumn, IPColumn, PairIDColumn, ExpiresAtColumn).
 		Values(
-			createSessionQuery.UserGUID, createSessionQuery.RefreshToken, createSessionQuery.UserAgent,
-			createSessionQuery.IP, createSessionQuery.PairID, createSessionQuery.ExpiresAt).
+			createSessionQuery.ID, createSessionQuery.UserGUID, createSessionQuery.RefreshToken, createSessionQuery.UserAgent,
+			createSessionQuery.IP.String(), createSessionQuery.PairID, createSessionQuery.ExpiresAt).
 		PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		return fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
@@ -50,3 +54,122 @@ func deleteSession(ctx context.Context, exec sqlexecutor, sessionID string) erro
 
 	return nil
 }
+
+func renewSession(ctx context.Context, exec sqlexecutor, renewSessionQuery *queries.RenewSessionQuery) error {
+	query, args, err := sq.Update(SessionsTable).
+		Set(RefreshTokenColumn, renewSessionQuery.RefreshToken).
+		Set(IPColumn, renewSessionQuery.IP.String()).
+		Set(PairIDColumn, renewSessionQuery.PairID).
+		Set(ExpiresAtColumn, renewSessionQuery.ExpiresAt).
+		Set(RefreshedAtColumn, time.Now()).
+		Where(sq.Eq{SessionIDColumn: renewSessionQuery.SessionID, PairIDColumn: renewSessionQuery.ConsumedPairID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	err = execAffectingRows(ctx, exec, query, args...)
+	if err != nil {
+		return err
+	}
+
+	query, args, err = sq.Insert(ConsumedRefreshTokensTable).
+		Columns(TokenDigestColumn, SessionIDRefColumn, ConsumedAtColumn, SuccessorColumn).
+		Values(renewSessionQuery.ConsumedTokenDigest, renewSessionQuery.SessionID, time.Now(), renewSessionQuery.ConsumedSuccessor).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	_, err = exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+
+	return nil
+}
+
+// evictUserSessions deletes the user's expired sessions and, with a positive limit,
+// the oldest ones so a new session fits within it.
+func evictUserSessions(ctx context.Context, exec sqlexecutor, userGUID string, maxUserSessions int) error {
+	condition := sq.Or{sq.LtOrEq{ExpiresAtColumn: time.Now()}}
+	if maxUserSessions > 0 {
+		condition = append(condition, sq.Expr(
+			fmt.Sprintf("%s NOT IN (SELECT %s FROM %s WHERE %s = ? ORDER BY %s DESC LIMIT ?)",
+				SessionIDColumn, SessionIDColumn, SessionsTable, UserIDColumn, CreatedAtColumn),
+			userGUID, maxUserSessions-1))
+	}
+
+	query, args, err := sq.Delete(SessionsTable).
+		Where(sq.Eq{UserIDColumn: userGUID}).
+		Where(condition).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	_, err = exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+
+	return nil
+}
+
+var sessionColumns = []string{SessionIDColumn, UserIDColumn, RefreshTokenColumn, UserAgentColumn,
+	IPColumn, PairIDColumn, ExpiresAtColumn, CreatedAtColumn, RefreshedAtColumn}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSession(row rowScanner) (*models.Session, error) {
+	var session models.Session
+	var ip string
+	var refreshedAt sql.NullTime
+
+	err := row.Scan(&session.ID, &session.UserGUID, &session.RefreshToken,
+		&session.UserAgent, &ip, &session.PairID, &session.ExpiresAt, &session.CreatedAt, &refreshedAt)
+	if err != nil {
+		return nil, err
+	}
+	session.RefreshedAt = refreshedAt.Time
+
+	session.IP, err = parseInet(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// parseInet converts the text form of a postgres INET value into an address, dropping any netmask.
+func parseInet(value string) (netip.Addr, error) {
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Addr{}, err
+		}
+		return prefix.Addr(), nil
+	}
+
+	return netip.ParseAddr(value)
+}
+
+// execAffectingRows executes the statement and reports repoerrors.ErrNotFound when it touched no rows.
+func execAffectingRows(ctx context.Context, exec sqlexecutor, query string, args ...any) error {
+	result, err := exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+	if affected == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
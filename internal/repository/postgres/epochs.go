@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"auth-service/internal/repository/repoerrors"
+	"auth-service/internal/types/queries"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// GetNotBefore returns the later of the global and the user's not-before epochs, zero when neither is set.
+func (s *postgresDB) GetNotBefore(ctx context.Context, userGUID string) (time.Time, error) {
+	query, args, err := sq.Select(fmt.Sprintf("MAX(%s)", NotBeforeColumn)).
+		From(NotBeforeEpochsTable).
+		Where(sq.Eq{ScopeColumn: []string{GlobalScope, userGUID}}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	var notBefore sql.NullTime
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&notBefore)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+
+	return notBefore.Time, nil
+}
+
+// BumpNotBefore moves the epoch of the user, or the global one for an empty user GUID, forward.
+// An epoch never moves back, the effective one is returned.
+func (s *postgresDB) BumpNotBefore(ctx context.Context, bumpNotBeforeQuery *queries.BumpNotBeforeQuery) (time.Time, error) {
+	scope := bumpNotBeforeQuery.UserGUID
+	if scope == "" {
+		scope = GlobalScope
+	}
+
+	query, args, err := sq.Insert(NotBeforeEpochsTable).
+		Columns(ScopeColumn, NotBeforeColumn).
+		Values(scope, bumpNotBeforeQuery.NotBefore).
+		Suffix(fmt.Sprintf("ON CONFLICT (%[1]s) DO UPDATE SET %[2]s = GREATEST(%[3]s.%[2]s, EXCLUDED.%[2]s) RETURNING %[2]s",
+			ScopeColumn, NotBeforeColumn, NotBeforeEpochsTable)).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	var notBefore time.Time
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&notBefore)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+
+	return notBefore, nil
+}
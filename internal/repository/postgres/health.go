@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"auth-service/internal/repository/repoerrors"
+	"auth-service/internal/types/models"
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+func (s *postgresDB) Ping(ctx context.Context) error {
+	err := s.db.PingContext(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrPing, err)
+	}
+
+	return nil
+}
+
+// SchemaVersion reads the migration version currently applied, which other replicas may have moved.
+func (s *postgresDB) SchemaVersion(ctx context.Context) (*models.SchemaVersion, error) {
+	query, args, err := sq.Select(VersionColumn, DirtyColumn).
+		From(SchemaMigrationsTable).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	version := models.SchemaVersion{
+		Expected: s.schemaVersion,
+	}
+
+	err = s.db.QueryRowContext(ctx, query, args...).Scan(&version.Current, &version.Dirty)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+
+	return &version, nil
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"auth-service/internal/repository/repoerrors"
+	"auth-service/internal/types/models"
+	"auth-service/internal/types/queries"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+)
+
+const uniqueViolationCode = "23505"
+
+// ListSigningKeys returns every key still usable for verification.
+func (s *postgresDB) ListSigningKeys(ctx context.Context) ([]*models.SigningKey, error) {
+	query, args, err := sq.Select(KeyIDColumn, AlgorithmColumn, PrivateKeyColumn, ActiveColumn,
+		CreatedAtColumn, ActivatedAtColumn, VerifyUntilColumn).
+		From(SigningKeysTable).
+		Where(sq.Or{sq.Eq{VerifyUntilColumn: nil}, sq.Gt{VerifyUntilColumn: time.Now()}}).
+		OrderBy(CreatedAtColumn).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+	defer rows.Close()
+
+	var keys []*models.SigningKey
+	for rows.Next() {
+		var key models.SigningKey
+		var activatedAt, verifyUntil sql.NullTime
+
+		err := rows.Scan(&key.ID, &key.Algorithm, &key.PrivateKey, &key.Active,
+			&key.CreatedAt, &activatedAt, &verifyUntil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+		}
+
+		key.ActivatedAt = activatedAt.Time
+		key.VerifyUntil = verifyUntil.Time
+		keys = append(keys, &key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+
+	return keys, nil
+}
+
+func (s *postgresDB) CreateSigningKey(ctx context.Context, createSigningKeyQuery *queries.CreateSigningKeyQuery) error {
+	query, args, err := sq.Insert(SigningKeysTable).
+		Columns(KeyIDColumn, AlgorithmColumn, PrivateKeyColumn, CreatedAtColumn).
+		Values(createSigningKeyQuery.ID, createSigningKeyQuery.Algorithm, createSigningKeyQuery.PrivateKey, time.Now()).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	_, err = s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode {
+			return fmt.Errorf("%w: %w", repoerrors.ErrAlreadyExists, err)
+		}
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+
+	return nil
+}
+
+// PromoteSigningKey makes the key the signing one, leaving the previously active key verification-only.
+func (s *postgresDB) PromoteSigningKey(ctx context.Context, promoteSigningKeyQuery *queries.PromoteSigningKeyQuery) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrTransactionBegin, err)
+	}
+
+	err = promoteSigningKey(ctx, tx, promoteSigningKeyQuery)
+	if err != nil {
+		txErr := tx.Rollback()
+		if txErr != nil {
+			return fmt.Errorf("%w + %w", err, txErr)
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrTransactionCommit, err)
+	}
+
+	return nil
+}
+
+// RetireSigningKey removes an inactive key, so tokens signed with it stop verifying.
+func (s *postgresDB) RetireSigningKey(ctx context.Context, keyID string) error {
+	query, args, err := sq.Delete(SigningKeysTable).
+		Where(sq.Eq{KeyIDColumn: keyID, ActiveColumn: false}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	return execAffectingRows(ctx, s.db, query, args...)
+}
+
+func (s *postgresDB) DeleteExpiredSigningKeys(ctx context.Context) error {
+	query, args, err := sq.Delete(SigningKeysTable).
+		Where(sq.LtOrEq{VerifyUntilColumn: time.Now()}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	_, err = s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+
+	return nil
+}
+
+func promoteSigningKey(ctx context.Context, exec sqlexecutor, promoteSigningKeyQuery *queries.PromoteSigningKeyQuery) error {
+	query, args, err := sq.Update(SigningKeysTable).
+		Set(ActiveColumn, false).
+		Set(VerifyUntilColumn, promoteSigningKeyQuery.DemotedVerifyUntil).
+		Where(sq.Eq{ActiveColumn: true}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	_, err = exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+
+	query, args, err = sq.Update(SigningKeysTable).
+		Set(ActiveColumn, true).
+		Set(ActivatedAtColumn, time.Now()).
+		Set(VerifyUntilColumn, nil).
+		Where(sq.Eq{KeyIDColumn: promoteSigningKeyQuery.ID}).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	return execAffectingRows(ctx, exec, query, args...)
+}
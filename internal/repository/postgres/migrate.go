@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"auth-service/internal/config"
+	"auth-service/internal/types/models"
+	"database/sql"
+	"embed"
+	"errors"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+const migrationsDir = "migrations"
+
+type migrator struct {
+	db      *sql.DB
+	migrate *migrate.Migrate
+}
+
+// NewMigrator connects to the database and applies the migrations embedded in the binary.
+func NewMigrator(cfg config.DBConn) (*migrator, error) {
+	database, err := sql.Open("postgres", cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.Ping()
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	m, err := newMigrate(database)
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+
+	return &migrator{
+		db:      database,
+		migrate: m,
+	}, nil
+}
+
+func newMigrate(database *sql.DB) (*migrate.Migrate, error) {
+	src, err := iofs.New(migrationsFS, migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := postgres.WithInstance(database, &postgres.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.NewWithInstance("iofs", src, "auth-db", driver)
+}
+
+// latestMigration is the version of the last embedded migration, the one the schema is expected at.
+func latestMigration() (uint, error) {
+	src, err := iofs.New(migrationsFS, migrationsDir)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// Up applies every pending migration.
+func (m *migrator) Up() error {
+	return ignoreNoChange(m.migrate.Up())
+}
+
+// Down rolls the given number of applied migrations back.
+func (m *migrator) Down(steps int) error {
+	return ignoreNoChange(m.migrate.Steps(-steps))
+}
+
+// To migrates up or down to the version.
+func (m *migrator) To(version uint) error {
+	return ignoreNoChange(m.migrate.Migrate(version))
+}
+
+// Force records the version as applied and clean without running any migration,
+// to recover from a failed one after fixing the schema by hand.
+func (m *migrator) Force(version int) error {
+	return m.migrate.Force(version)
+}
+
+func (m *migrator) Status() (*models.SchemaVersion, error) {
+	expected, err := latestMigration()
+	if err != nil {
+		return nil, err
+	}
+
+	current, dirty, err := m.migrate.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, err
+	}
+
+	return &models.SchemaVersion{
+		Current:  current,
+		Expected: expected,
+		Dirty:    dirty,
+	}, nil
+}
+
+func (m *migrator) Close() error {
+	srcErr, dbErr := m.migrate.Close()
+	return errors.Join(srcErr, dbErr, m.db.Close())
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,8 @@
+-- The dropped columns can't be restored, so the consumed tokens are forgotten.
+DELETE FROM consumed_refresh_tokens;
+ALTER TABLE consumed_refresh_tokens ADD COLUMN IF NOT EXISTS pair_id UUID NOT NULL;
+ALTER TABLE consumed_refresh_tokens ADD COLUMN IF NOT EXISTS refresh_token TEXT NOT NULL;
+ALTER TABLE consumed_refresh_tokens DROP CONSTRAINT IF EXISTS consumed_refresh_tokens_pkey;
+ALTER TABLE consumed_refresh_tokens ADD PRIMARY KEY (pair_id);
+ALTER TABLE consumed_refresh_tokens ALTER COLUMN token_digest DROP NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS consumed_refresh_tokens_token_digest_idx ON consumed_refresh_tokens (token_digest);
@@ -0,0 +1,9 @@
+-- Consumed tokens are identified by the digest of the token alone: the pair ID
+-- and the refresh token hash are never read back.
+DELETE FROM consumed_refresh_tokens WHERE token_digest IS NULL;
+ALTER TABLE consumed_refresh_tokens DROP CONSTRAINT IF EXISTS consumed_refresh_tokens_pkey;
+DROP INDEX IF EXISTS consumed_refresh_tokens_token_digest_idx;
+ALTER TABLE consumed_refresh_tokens ALTER COLUMN token_digest SET NOT NULL;
+ALTER TABLE consumed_refresh_tokens ADD PRIMARY KEY (token_digest);
+ALTER TABLE consumed_refresh_tokens DROP COLUMN IF EXISTS pair_id;
+ALTER TABLE consumed_refresh_tokens DROP COLUMN IF EXISTS refresh_token;
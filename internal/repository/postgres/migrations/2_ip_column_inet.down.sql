@@ -0,0 +1 @@
+ALTER TABLE sessions ALTER COLUMN ip TYPE VARCHAR(45) USING host(ip);
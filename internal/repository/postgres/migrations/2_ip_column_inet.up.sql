@@ -0,0 +1,12 @@
+-- The column was filled from an unvalidated header: sessions holding anything but an address are dropped,
+-- their users simply log in again.
+CREATE FUNCTION pg_temp.try_inet(value TEXT) RETURNS INET AS $$
+BEGIN
+    RETURN value::INET;
+EXCEPTION WHEN invalid_text_representation THEN
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DELETE FROM sessions WHERE pg_temp.try_inet(ip) IS NULL;
+ALTER TABLE sessions ALTER COLUMN ip TYPE INET USING ip::INET;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS signing_keys (
+    kid TEXT PRIMARY KEY,
+    algorithm TEXT NOT NULL,
+    private_key TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    activated_at TIMESTAMP,
+    verify_until TIMESTAMP
+);
+
+-- At most one key signs, at most one key waits for promotion.
+CREATE UNIQUE INDEX IF NOT EXISTS signing_keys_single_active ON signing_keys (active) WHERE active;
+CREATE UNIQUE INDEX IF NOT EXISTS signing_keys_single_pending ON signing_keys ((activated_at IS NULL)) WHERE activated_at IS NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS sessions_user_id_created_at_idx;
+ALTER TABLE sessions DROP COLUMN IF EXISTS refreshed_at;
+DELETE FROM sessions a USING sessions b
+    WHERE a.user_id = b.user_id AND a.user_agent = b.user_agent AND (a.created_at, a.id) < (b.created_at, b.id);
+ALTER TABLE sessions ADD CONSTRAINT sessions_user_id_user_agent_key UNIQUE (user_id, user_agent);
@@ -0,0 +1,3 @@
+ALTER TABLE sessions DROP CONSTRAINT IF EXISTS sessions_user_id_user_agent_key;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS refreshed_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS sessions_user_id_created_at_idx ON sessions (user_id, created_at);
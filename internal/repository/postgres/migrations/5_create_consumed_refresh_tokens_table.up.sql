@@ -0,0 +1,10 @@
+-- Refresh tokens already rotated out of a session. The session ID survives rotation
+-- and identifies the token family.
+CREATE TABLE IF NOT EXISTS consumed_refresh_tokens (
+    pair_id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+    refresh_token TEXT NOT NULL,
+    consumed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS consumed_refresh_tokens_session_id_idx ON consumed_refresh_tokens (session_id);
@@ -0,0 +1 @@
+ALTER TABLE consumed_refresh_tokens DROP COLUMN IF EXISTS successor;
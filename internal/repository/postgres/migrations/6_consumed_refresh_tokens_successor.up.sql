@@ -0,0 +1,2 @@
+-- Token pair issued in exchange for the consumed one, sealed with the consumed refresh token.
+ALTER TABLE consumed_refresh_tokens ADD COLUMN IF NOT EXISTS successor BYTEA;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS consumed_refresh_tokens_token_digest_idx;
+ALTER TABLE consumed_refresh_tokens DROP COLUMN IF EXISTS token_digest;
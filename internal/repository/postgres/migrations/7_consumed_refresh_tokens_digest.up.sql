@@ -0,0 +1,4 @@
+-- Refresh tokens no longer come with an access token naming their pair,
+-- consumed tokens are looked up by a digest of the token itself.
+ALTER TABLE consumed_refresh_tokens ADD COLUMN IF NOT EXISTS token_digest TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS consumed_refresh_tokens_token_digest_idx ON consumed_refresh_tokens (token_digest);
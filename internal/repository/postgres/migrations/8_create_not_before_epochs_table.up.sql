@@ -0,0 +1,5 @@
+-- Tokens issued before the epoch of their user or the global one ('*') are rejected.
+CREATE TABLE IF NOT EXISTS not_before_epochs (
+    scope TEXT PRIMARY KEY,
+    not_before TIMESTAMP NOT NULL
+);
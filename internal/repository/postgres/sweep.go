@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"auth-service/internal/repository/repoerrors"
+	"auth-service/internal/types/queries"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// unlockTimeout bounds the advisory lock release, which must outlive the sweep context
+// but not hang on a stuck connection.
+const unlockTimeout = 5 * time.Second
+
+// DeleteExpiredSessions holds a session-level advisory lock on a reserved connection for the whole sweep,
+// so the batches commit one by one while other replicas skip the sweep.
+func (s *postgresDB) DeleteExpiredSessions(ctx context.Context, deleteExpiredSessionsQuery *queries.DeleteExpiredSessionsQuery) (deleted int64, locked bool, err error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+	defer conn.Close()
+
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", SessionSweepLock).Scan(&locked)
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+	}
+	if !locked {
+		return 0, false, nil
+	}
+
+	defer func() {
+		// A lock left held would follow the connection back into the pool, so a connection
+		// failing to release it is discarded instead.
+		unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), unlockTimeout)
+		defer cancel()
+
+		_, unlockErr := conn.ExecContext(unlockCtx, "SELECT pg_advisory_unlock($1)", SessionSweepLock)
+		if unlockErr != nil {
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+			err = errors.Join(err, fmt.Errorf("%w: %w", repoerrors.ErrLockRelease, unlockErr))
+		}
+	}()
+
+	query, args, err := sq.Delete(SessionsTable).
+		Where(sq.Expr(
+			fmt.Sprintf("%s IN (SELECT %s FROM %s WHERE %s <= ? LIMIT ?)",
+				SessionIDColumn, SessionIDColumn, SessionsTable, ExpiresAtColumn),
+			deleteExpiredSessionsQuery.ExpiredBefore, deleteExpiredSessionsQuery.BatchSize)).
+		PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return 0, true, fmt.Errorf("%w: %w", repoerrors.ErrQueryBuilding, err)
+	}
+
+	for {
+		result, err := conn.ExecContext(ctx, query, args...)
+		if err != nil {
+			return deleted, true, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+		}
+
+		batch, err := result.RowsAffected()
+		if err != nil {
+			return deleted, true, fmt.Errorf("%w: %w", repoerrors.ErrQueryExec, err)
+		}
+		deleted += batch
+
+		if batch < int64(deleteExpiredSessionsQuery.BatchSize) {
+			return deleted, true, nil
+		}
+	}
+}
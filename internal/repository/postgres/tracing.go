@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "auth-service/repository"
+
+// tracedDB traces every query run on the database or in its transactions.
+type tracedDB struct {
+	*sql.DB
+}
+
+type tracedTx struct {
+	*sql.Tx
+}
+
+type tracedConn struct {
+	*sql.Conn
+}
+
+func (db *tracedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*tracedTx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedTx{Tx: tx}, nil
+}
+
+// Conn reserves a connection, for statements bound to a database session such as advisory locks.
+func (db *tracedDB) Conn(ctx context.Context) (*tracedConn, error) {
+	conn, err := db.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: conn}, nil
+}
+
+func (db *tracedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return tracedExec(ctx, db.DB, query, args...)
+}
+
+func (db *tracedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return tracedQuery(ctx, db.DB, query, args...)
+}
+
+func (db *tracedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return tracedQueryRow(ctx, db.DB, query, args...)
+}
+
+func (tx *tracedTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return tracedExec(ctx, tx.Tx, query, args...)
+}
+
+func (tx *tracedTx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return tracedQuery(ctx, tx.Tx, query, args...)
+}
+
+func (tx *tracedTx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return tracedQueryRow(ctx, tx.Tx, query, args...)
+}
+
+func (conn *tracedConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return tracedExec(ctx, conn.Conn, query, args...)
+}
+
+func (conn *tracedConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return tracedQuery(ctx, conn.Conn, query, args...)
+}
+
+func (conn *tracedConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return tracedQueryRow(ctx, conn.Conn, query, args...)
+}
+
+func tracedExec(ctx context.Context, exec sqlexecutor, query string, args ...any) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	result, err := exec.ExecContext(ctx, query, args...)
+	recordQueryError(span, err)
+	return result, err
+}
+
+func tracedQuery(ctx context.Context, exec sqlexecutor, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	rows, err := exec.QueryContext(ctx, query, args...)
+	recordQueryError(span, err)
+	return rows, err
+}
+
+func tracedQueryRow(ctx context.Context, exec sqlexecutor, query string, args ...any) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+
+	row := exec.QueryRowContext(ctx, query, args...)
+	if err := row.Err(); err != sql.ErrNoRows {
+		recordQueryError(span, err)
+	}
+	return row
+}
+
+// startQuerySpan names the span after the SQL operation. The statement is recorded as built,
+// with placeholders only: arguments such as token hashes never reach the trace.
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	statement := strings.Join(strings.Fields(query), " ")
+	operation, _, _ := strings.Cut(statement, " ")
+
+	return otel.Tracer(tracerName).Start(ctx, "postgres "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", operation),
+			attribute.String("db.query.text", statement),
+		))
+}
+
+func recordQueryError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
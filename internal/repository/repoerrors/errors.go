@@ -8,4 +8,9 @@ var (
 	ErrTransactionCommit = errors.New("repo: tx commit failed")
 	ErrQueryBuilding     = errors.New("repo: query building failed")
 	ErrQueryExec         = errors.New("repo: query execution failed")
+	ErrNotFound          = errors.New("repo: record not found")
+	ErrAlreadyExists     = errors.New("repo: record already exists")
+	ErrPing              = errors.New("repo: database unreachable")
+	ErrLockRelease       = errors.New("repo: advisory lock release failed")
+	ErrSchemaMismatch    = errors.New("repo: schema not at the latest migration, run \"migrate\" first")
 )
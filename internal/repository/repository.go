@@ -6,15 +6,58 @@ import (
 	"auth-service/internal/types/models"
 	"auth-service/internal/types/queries"
 	"context"
+	"time"
 )
 
 type Repository interface {
 	GetSession(ctx context.Context, getSession *queries.GetSessionQuery) (*models.Session, error)
 	CreateSession(ctx context.Context, createSession *queries.CreateSessionQuery) error
 	DeleteSession(ctx context.Context, sessionID string) error
-	RenewSession(ctx context.Context, oldSessionID string, createSession *queries.CreateSessionQuery) error
+	RenewSession(ctx context.Context, renewSession *queries.RenewSessionQuery) error
+	GetConsumedRefreshToken(ctx context.Context, tokenDigest string) (*models.ConsumedRefreshToken, error)
+	ListUserSessions(ctx context.Context, userGUID string) ([]*models.Session, error)
+	DeleteUserSession(ctx context.Context, deleteUserSession *queries.DeleteUserSessionQuery) error
+	DeleteOtherUserSessions(ctx context.Context, deleteOtherUserSessions *queries.DeleteOtherUserSessionsQuery) error
+	CountActiveSessions(ctx context.Context) (int, error)
+	// DeleteExpiredSessions deletes expired sessions in batches under an advisory lock.
+	// When another replica holds the lock nothing is deleted and locked is false.
+	DeleteExpiredSessions(ctx context.Context, deleteExpiredSessions *queries.DeleteExpiredSessionsQuery) (deleted int64, locked bool, err error)
+
+	ListSigningKeys(ctx context.Context) ([]*models.SigningKey, error)
+	CreateSigningKey(ctx context.Context, createSigningKey *queries.CreateSigningKeyQuery) error
+	PromoteSigningKey(ctx context.Context, promoteSigningKey *queries.PromoteSigningKeyQuery) error
+	RetireSigningKey(ctx context.Context, keyID string) error
+	DeleteExpiredSigningKeys(ctx context.Context) error
+
+	GetNotBefore(ctx context.Context, userGUID string) (time.Time, error)
+	BumpNotBefore(ctx context.Context, bumpNotBefore *queries.BumpNotBeforeQuery) (time.Time, error)
+
+	Ping(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (*models.SchemaVersion, error)
+
+	// Close closes the connection pool, waiting for queries in progress.
+	Close() error
+}
+
+// Migrator applies the schema migrations embedded in the binary.
+type Migrator interface {
+	Up() error
+	Down(steps int) error
+	To(version uint) error
+	Force(version int) error
+	Status() (*models.SchemaVersion, error)
+	Close() error
 }
 
 func NewPostgresRepo(cfg config.DBConn) (Repository, error) {
 	return postgres.New(cfg)
 }
+
+// OpenPostgresRepo connects without migrating, for tools that must not change the schema.
+func OpenPostgresRepo(ctx context.Context, cfg config.DBConn) (Repository, error) {
+	return postgres.Open(ctx, cfg)
+}
+
+func NewPostgresMigrator(cfg config.DBConn) (Migrator, error) {
+	return postgres.NewMigrator(cfg)
+}
@@ -0,0 +1,9 @@
+package revocation
+
+import "errors"
+
+// Revocation check errors.
+var (
+	ErrSessionLookup = errors.New("revocation: session lookup failed")
+	ErrEpochLookup   = errors.New("revocation: not-before epoch lookup failed")
+)
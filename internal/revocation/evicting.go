@@ -0,0 +1,55 @@
+package revocation
+
+import (
+	"auth-service/internal/repository"
+	"auth-service/internal/types/queries"
+	"context"
+	"time"
+)
+
+// evictingRepository drops the cached revocation state touched by the deletes and epoch bumps
+// of the wrapped repository, so tokens revoked on this replica stop working here at once.
+type evictingRepository struct {
+	repository.Repository
+	checker Checker
+}
+
+func NewEvicting(next repository.Repository, checker Checker) repository.Repository {
+	return &evictingRepository{
+		Repository: next,
+		checker:    checker,
+	}
+}
+
+// CreateSession may evict the oldest sessions of the user over the session limit.
+func (r *evictingRepository) CreateSession(ctx context.Context, createSession *queries.CreateSessionQuery) error {
+	err := r.Repository.CreateSession(ctx, createSession)
+	if createSession.MaxUserSessions > 0 {
+		r.checker.ForgetUserSessions(createSession.UserGUID)
+	}
+	return err
+}
+
+func (r *evictingRepository) DeleteSession(ctx context.Context, sessionID string) error {
+	err := r.Repository.DeleteSession(ctx, sessionID)
+	r.checker.ForgetSession(sessionID)
+	return err
+}
+
+func (r *evictingRepository) DeleteUserSession(ctx context.Context, deleteUserSession *queries.DeleteUserSessionQuery) error {
+	err := r.Repository.DeleteUserSession(ctx, deleteUserSession)
+	r.checker.ForgetSession(deleteUserSession.SessionID)
+	return err
+}
+
+func (r *evictingRepository) DeleteOtherUserSessions(ctx context.Context, deleteOtherUserSessions *queries.DeleteOtherUserSessionsQuery) error {
+	err := r.Repository.DeleteOtherUserSessions(ctx, deleteOtherUserSessions)
+	r.checker.ForgetUserSessions(deleteOtherUserSessions.UserGUID)
+	return err
+}
+
+func (r *evictingRepository) BumpNotBefore(ctx context.Context, bumpNotBefore *queries.BumpNotBeforeQuery) (time.Time, error) {
+	notBefore, err := r.Repository.BumpNotBefore(ctx, bumpNotBefore)
+	r.checker.ForgetNotBefore(bumpNotBefore.UserGUID)
+	return notBefore, err
+}
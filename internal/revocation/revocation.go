@@ -0,0 +1,121 @@
+package revocation
+
+import (
+	"auth-service/internal/repository"
+	"auth-service/internal/types/queries"
+	"context"
+	"fmt"
+	"time"
+)
+
+// Checker tells whether an access token was revoked before it expired:
+// its session was deleted, or it was issued before a not-before epoch.
+type Checker interface {
+	// Active reports whether the session is alive, always true when the session check is disabled.
+	Active(ctx context.Context, sessionID string) (bool, error)
+	// NotBefore is the cutoff for tokens of the user, zero when no epoch is set.
+	NotBefore(ctx context.Context, userGUID string) (time.Time, error)
+
+	// ForgetSession drops the cached state of a session deleted on this replica.
+	ForgetSession(sessionID string)
+	// ForgetUserSessions drops the cached state of every session of the user.
+	ForgetUserSessions(userGUID string)
+	// ForgetNotBefore drops the cached epoch of the user, or every cached epoch for the global one.
+	ForgetNotBefore(userGUID string)
+}
+
+// IssuedBefore reports whether a token issued at issuedAt is cut off by the not-before epoch.
+// The iat claim has whole-second precision, so both are compared by the second: a token issued
+// in the same second as the bump stays valid rather than being rejected for its whole lifetime.
+func IssuedBefore(issuedAt, notBefore time.Time) bool {
+	return issuedAt.Truncate(time.Second).Before(notBefore.Truncate(time.Second))
+}
+
+type cachedChecker struct {
+	repo          repository.Repository
+	checkSessions bool
+	sessions      *ttlCache[sessionState]
+	epochs        *ttlCache[time.Time]
+}
+
+type sessionState struct {
+	active bool
+	// userGUID is empty for sessions not found.
+	userGUID string
+}
+
+// New returns a checker looking sessions and epochs up in the repository and caching the answers for ttl,
+// which bounds how long a revocation takes to apply on this replica.
+func New(repo repository.Repository, checkSessions bool, ttl time.Duration) Checker {
+	return &cachedChecker{
+		repo:          repo,
+		checkSessions: checkSessions,
+		sessions:      newTTLCache[sessionState](ttl),
+		epochs:        newTTLCache[time.Time](ttl),
+	}
+}
+
+func (c *cachedChecker) Active(ctx context.Context, sessionID string) (bool, error) {
+	if !c.checkSessions {
+		return true, nil
+	}
+
+	if state, ok := c.sessions.get(sessionID); ok {
+		return state.active, nil
+	}
+
+	session, err := c.repo.GetSession(ctx, &queries.GetSessionQuery{
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", ErrSessionLookup, err)
+	}
+
+	if session == nil {
+		c.sessions.set(sessionID, sessionState{})
+		return false, nil
+	}
+
+	active := time.Now().Before(session.ExpiresAt)
+	state := sessionState{active: active, userGUID: session.UserGUID}
+	if active {
+		c.sessions.setUntil(sessionID, state, session.ExpiresAt)
+	} else {
+		c.sessions.set(sessionID, state)
+	}
+
+	return active, nil
+}
+
+func (c *cachedChecker) NotBefore(ctx context.Context, userGUID string) (time.Time, error) {
+	if notBefore, ok := c.epochs.get(userGUID); ok {
+		return notBefore, nil
+	}
+
+	notBefore, err := c.repo.GetNotBefore(ctx, userGUID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrEpochLookup, err)
+	}
+
+	c.epochs.set(userGUID, notBefore)
+
+	return notBefore, nil
+}
+
+func (c *cachedChecker) ForgetSession(sessionID string) {
+	c.sessions.delete(sessionID)
+}
+
+func (c *cachedChecker) ForgetUserSessions(userGUID string) {
+	c.sessions.deleteFunc(func(state sessionState) bool {
+		return state.userGUID == userGUID
+	})
+}
+
+func (c *cachedChecker) ForgetNotBefore(userGUID string) {
+	if userGUID == "" {
+		c.epochs.clear()
+		return
+	}
+	c.epochs.delete(userGUID)
+}
@@ -0,0 +1,33 @@
+package revocation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIssuedBefore(t *testing.T) {
+	bump := time.Date(2026, 10, 18, 12, 0, 0, 400_000_000, time.UTC)
+
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		want     bool
+	}{
+		{"previous second", bump.Add(-time.Second), true},
+		{"same second, iat truncated", bump.Truncate(time.Second), false},
+		{"same second, after the bump", bump.Add(100 * time.Millisecond), false},
+		{"next second", bump.Add(time.Second).Truncate(time.Second), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IssuedBefore(test.issuedAt, bump); got != test.want {
+				t.Errorf("IssuedBefore(%s, %s) = %v, want %v", test.issuedAt, bump, got, test.want)
+			}
+		})
+	}
+
+	if IssuedBefore(bump, time.Time{}) {
+		t.Error("IssuedBefore with no epoch set = true, want false")
+	}
+}
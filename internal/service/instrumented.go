@@ -0,0 +1,124 @@
+package service
+
+import (
+	"auth-service/internal/metrics"
+	"auth-service/internal/service/serverrors"
+	"auth-service/internal/types/dto"
+	"auth-service/internal/types/models"
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "auth-service/service"
+
+// instrumentedService traces every operation of the wrapped service and counts its outcomes.
+type instrumentedService struct {
+	next    Service
+	metrics *metrics.Metrics
+}
+
+func NewInstrumented(next Service, m *metrics.Metrics) Service {
+	return &instrumentedService{
+		next:    next,
+		metrics: m,
+	}
+}
+
+func (s *instrumentedService) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "service."+operation)
+}
+
+func (s *instrumentedService) observe(span trace.Span, operation string, err error) {
+	defer span.End()
+
+	outcome := metrics.OutcomeOK
+	if err != nil {
+		outcome = serverrors.Kind(err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, outcome)
+	}
+	span.SetAttributes(attribute.String("auth.outcome", outcome))
+	s.metrics.ObserveOutcome(operation, outcome)
+}
+
+func (s *instrumentedService) Login(ctx context.Context, login *models.Login) (*dto.LoginResponse, *http.Cookie, error) {
+	ctx, span := s.start(ctx, "login")
+	response, cookie, err := s.next.Login(ctx, login)
+	s.observe(span, "login", err)
+	return response, cookie, err
+}
+
+func (s *instrumentedService) GetCurrentUser(ctx context.Context) (*dto.UserResponse, error) {
+	ctx, span := s.start(ctx, "get_current_user")
+	response, err := s.next.GetCurrentUser(ctx)
+	s.observe(span, "get_current_user", err)
+	return response, err
+}
+
+func (s *instrumentedService) Refresh(ctx context.Context, refresh *models.Refresh) (*dto.RefreshResponse, *http.Cookie, error) {
+	ctx, span := s.start(ctx, "refresh")
+	response, cookie, err := s.next.Refresh(ctx, refresh)
+	s.observe(span, "refresh", err)
+	return response, cookie, err
+}
+
+func (s *instrumentedService) Logout(ctx context.Context) error {
+	ctx, span := s.start(ctx, "logout")
+	err := s.next.Logout(ctx)
+	s.observe(span, "logout", err)
+	return err
+}
+
+func (s *instrumentedService) ListSessions(ctx context.Context) (*dto.SessionsResponse, error) {
+	ctx, span := s.start(ctx, "list_sessions")
+	response, err := s.next.ListSessions(ctx)
+	s.observe(span, "list_sessions", err)
+	return response, err
+}
+
+func (s *instrumentedService) RevokeSession(ctx context.Context, sessionID string) error {
+	ctx, span := s.start(ctx, "revoke_session")
+	err := s.next.RevokeSession(ctx, sessionID)
+	s.observe(span, "revoke_session", err)
+	return err
+}
+
+func (s *instrumentedService) RevokeOtherSessions(ctx context.Context) error {
+	ctx, span := s.start(ctx, "revoke_other_sessions")
+	err := s.next.RevokeOtherSessions(ctx)
+	s.observe(span, "revoke_other_sessions", err)
+	return err
+}
+
+func (s *instrumentedService) GetJWKS(ctx context.Context) *dto.JWKSResponse {
+	ctx, span := s.start(ctx, "get_jwks")
+	response := s.next.GetJWKS(ctx)
+	s.observe(span, "get_jwks", nil)
+	return response
+}
+
+func (s *instrumentedService) BumpNotBefore(ctx context.Context, userGUID string) (*dto.NotBeforeResponse, error) {
+	ctx, span := s.start(ctx, "bump_not_before")
+	response, err := s.next.BumpNotBefore(ctx, userGUID)
+	s.observe(span, "bump_not_before", err)
+	return response, err
+}
+
+func (s *instrumentedService) Introspect(ctx context.Context, request *models.TokenRequest) (*dto.IntrospectionResponse, error) {
+	ctx, span := s.start(ctx, "introspect")
+	response, err := s.next.Introspect(ctx, request)
+	s.observe(span, "introspect", err)
+	return response, err
+}
+
+func (s *instrumentedService) Revoke(ctx context.Context, request *models.TokenRequest) error {
+	ctx, span := s.start(ctx, "revoke")
+	err := s.next.Revoke(ctx, request)
+	s.observe(span, "revoke", err)
+	return err
+}
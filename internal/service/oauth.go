@@ -0,0 +1,180 @@
+package service
+
+import (
+	"auth-service/internal/mappers/dtomap"
+	"auth-service/internal/middleware"
+	"auth-service/internal/revocation"
+	"auth-service/internal/service/serverrors"
+	"auth-service/internal/tokenizer"
+	"auth-service/internal/types/dto"
+	"auth-service/internal/types/models"
+	"auth-service/internal/types/queries"
+	"auth-service/pkg/cryptor"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Introspect describes the token as RFC 7662 requires. Unknown, expired and revoked tokens are
+// merely inactive, errors mean the token state could not be told. The type hint only decides
+// which type is tried first.
+func (s *authService) Introspect(ctx context.Context, request *models.TokenRequest) (*dto.IntrospectionResponse, error) {
+	info, err := s.lookupToken(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return dtomap.MapToIntrospectionResponse(info), nil
+}
+
+// Revoke deletes the session of the token as RFC 7009 describes, revoking every token of the session.
+// Unknown and already inactive tokens are not an error. Only the clients granted revocation may call it.
+func (s *authService) Revoke(ctx context.Context, request *models.TokenRequest) error {
+	clientID, ok := ctx.Value(middleware.ClientIDKey).(string)
+	if !ok {
+		return serverrors.ErrClientIDExtraction
+	}
+
+	if !slices.Contains(s.oauthCfg.RevokingClients, clientID) {
+		return fmt.Errorf("%w: %s", serverrors.ErrClientNotAllowed, clientID)
+	}
+
+	info, err := s.lookupToken(ctx, request)
+	if err != nil || info == nil {
+		return err
+	}
+
+	err = s.repo.DeleteSession(ctx, info.Session.ID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", serverrors.ErrDeleteSession, err)
+	}
+
+	return nil
+}
+
+// lookupToken returns the info of an active token of either type, nil for an inactive one.
+func (s *authService) lookupToken(ctx context.Context, request *models.TokenRequest) (*models.TokenInfo, error) {
+	if request.Token == "" {
+		return nil, nil
+	}
+
+	lookups := []func(context.Context, string) (*models.TokenInfo, error){s.lookupAccessToken, s.lookupRefreshToken}
+	if request.TokenTypeHint == models.TokenTypeRefresh {
+		lookups[0], lookups[1] = lookups[1], lookups[0]
+	}
+
+	for _, lookup := range lookups {
+		info, err := lookup(ctx, request.Token)
+		if err != nil || info != nil {
+			return info, err
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *authService) lookupAccessToken(ctx context.Context, token string) (*models.TokenInfo, error) {
+	claims, err := s.tokenizer.VerifyAccessTokenJWT(token, false)
+	if err != nil {
+		return nil, nil
+	}
+
+	userGUID, err := claims.GetSubject()
+	if err != nil {
+		return nil, nil
+	}
+
+	sessionID, ok := claims[tokenizer.SessionClaimsKey].(string)
+	if !ok {
+		return nil, nil
+	}
+
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil {
+		return nil, nil
+	}
+
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return nil, nil
+	}
+
+	session, err := s.repo.GetSession(ctx, &queries.GetSessionQuery{
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serverrors.ErrGetSession, err)
+	}
+	if session == nil || session.UserGUID != userGUID {
+		return nil, nil
+	}
+
+	live, err := s.sessionLive(ctx, session, issuedAt.Time)
+	if err != nil || !live {
+		return nil, err
+	}
+
+	return s.tokenInfo(models.TokenTypeAccess, session, issuedAt.Time, expiresAt.Time), nil
+}
+
+func (s *authService) lookupRefreshToken(ctx context.Context, token string) (*models.TokenInfo, error) {
+	sessionID, err := tokenizer.ParseRefreshToken(token)
+	if err != nil {
+		return nil, nil
+	}
+
+	session, err := s.repo.GetSession(ctx, &queries.GetSessionQuery{
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serverrors.ErrGetSession, err)
+	}
+	if session == nil {
+		return nil, nil
+	}
+
+	err = s.cryptor.CompareHashAndKeyword(ctx, session.RefreshToken, token)
+	if err != nil {
+		if errors.Is(err, cryptor.ErrHashMismatch) {
+			return nil, nil
+		}
+		return nil, cryptorError(err, serverrors.ErrHashingProcess)
+	}
+
+	live, err := s.sessionLive(ctx, session, refreshIssuedAt(session))
+	if err != nil || !live {
+		return nil, err
+	}
+
+	return s.tokenInfo(models.TokenTypeRefresh, session, refreshIssuedAt(session), session.ExpiresAt), nil
+}
+
+// sessionLive reports whether the session is neither expired nor cut off by a not-before epoch
+// for tokens issued at issuedAt.
+func (s *authService) sessionLive(ctx context.Context, session *models.Session, issuedAt time.Time) (bool, error) {
+	if time.Now().After(session.ExpiresAt) {
+		return false, nil
+	}
+
+	notBefore, err := s.repo.GetNotBefore(ctx, session.UserGUID)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", serverrors.ErrGetNotBefore, err)
+	}
+
+	return !revocation.IssuedBefore(issuedAt, notBefore), nil
+}
+
+func (s *authService) tokenInfo(tokenType string, session *models.Session, issuedAt, expiresAt time.Time) *models.TokenInfo {
+	return &models.TokenInfo{
+		Type:      tokenType,
+		Subject:   session.UserGUID,
+		ExpiresAt: expiresAt,
+		IssuedAt:  issuedAt,
+		Issuer:    s.tokenizer.Issuer(),
+		ClientID:  s.oauthCfg.ClientID,
+		Scope:     s.oauthCfg.Scope,
+		Session:   session,
+	}
+}
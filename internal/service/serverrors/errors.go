@@ -8,14 +8,63 @@ var (
 	ErrIpAddressInvalid      = errors.New("invalid ip address fromat")
 	ErrAccessTokenGeneration = errors.New("access token generation failed")
 	ErrHashingProcess        = errors.New("hashing process failed")
-	ErrSessionAlreadyExists  = errors.New("active session is already exist")
+	ErrHashingOverloaded     = errors.New("hashing capacity exhausted")
 	ErrGUIDExtraction        = errors.New("user guid extraction from context failed")
-	ErrOldAccessTokenInvalid = errors.New("old access token invalid")
+	ErrSessionIDExtraction   = errors.New("session id extraction from context failed")
 	ErrNoRefreshSession      = errors.New("no refresh session found")
-	ErrTokenPairInvalid      = errors.New("invalid token pair")
 	ErrRefreshTokenInvalid   = errors.New("invalid refresh token")
+	ErrRefreshTokenReused    = errors.New("refresh token reused")
+	ErrSessionIPChanged      = errors.New("session ip address changed")
+	ErrSessionIDInvalid      = errors.New("invalid session id")
+	ErrSessionNotFound       = errors.New("session not found")
 	ErrGetSession            = errors.New("get session failed")
+	ErrListSessions          = errors.New("list sessions failed")
+	ErrGetConsumedToken      = errors.New("get consumed refresh token failed")
 	ErrCreateSession         = errors.New("create session failed")
 	ErrDeleteSession         = errors.New("delete session failed")
 	ErrRenewSession          = errors.New("renew session failed")
+	ErrGetNotBefore          = errors.New("get not-before epoch failed")
+	ErrBumpNotBefore         = errors.New("bump not-before epoch failed")
+	ErrClientNotAllowed      = errors.New("client not allowed to revoke tokens")
+	ErrClientIDExtraction    = errors.New("client id extraction from context failed")
 )
+
+// kinds names the errors for metrics labels, matched in order.
+var kinds = []struct {
+	err  error
+	name string
+}{
+	{ErrUserGUIDInvalid, "user_guid_invalid"},
+	{ErrIpAddressInvalid, "ip_address_invalid"},
+	{ErrAccessTokenGeneration, "access_token_generation"},
+	{ErrHashingOverloaded, "hashing_overloaded"},
+	{ErrHashingProcess, "hashing_process"},
+	{ErrGUIDExtraction, "guid_extraction"},
+	{ErrSessionIDExtraction, "session_id_extraction"},
+	{ErrNoRefreshSession, "no_refresh_session"},
+	{ErrRefreshTokenInvalid, "refresh_token_invalid"},
+	{ErrRefreshTokenReused, "refresh_token_reused"},
+	{ErrSessionIPChanged, "session_ip_changed"},
+	{ErrSessionIDInvalid, "session_id_invalid"},
+	{ErrSessionNotFound, "session_not_found"},
+	{ErrGetSession, "get_session"},
+	{ErrListSessions, "list_sessions"},
+	{ErrGetConsumedToken, "get_consumed_token"},
+	{ErrCreateSession, "create_session"},
+	{ErrDeleteSession, "delete_session"},
+	{ErrRenewSession, "renew_session"},
+	{ErrGetNotBefore, "get_not_before"},
+	{ErrBumpNotBefore, "bump_not_before"},
+	{ErrClientNotAllowed, "client_not_allowed"},
+	{ErrClientIDExtraction, "client_id_extraction"},
+}
+
+// Kind names the service error for metrics labels, "unknown" for errors not listed.
+func Kind(err error) string {
+	for _, kind := range kinds {
+		if errors.Is(err, kind.err) {
+			return kind.name
+		}
+	}
+	return "unknown"
+}
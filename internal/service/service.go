@@ -1,9 +1,13 @@
 package service
 
 import (
+	"auth-service/internal/config"
 	"auth-service/internal/mappers/dtomap"
 	"auth-service/internal/middleware"
+	"auth-service/internal/notifier"
 	"auth-service/internal/repository"
+	"auth-service/internal/repository/repoerrors"
+	"auth-service/internal/revocation"
 	"auth-service/internal/service/serverrors"
 	"auth-service/internal/tokenizer"
 	"auth-service/internal/types/dto"
@@ -11,9 +15,11 @@ import (
 	"auth-service/internal/types/queries"
 	"auth-service/pkg/cryptor"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,21 +29,35 @@ type Service interface {
 	Login(ctx context.Context, login *models.Login) (*dto.LoginResponse, *http.Cookie, error)
 	GetCurrentUser(ctx context.Context) (*dto.UserResponse, error)
 	Refresh(ctx context.Context, refresh *models.Refresh) (*dto.RefreshResponse, *http.Cookie, error)
-	Logout(ctx context.Context, userAgent string) error
+	Logout(ctx context.Context) error
+	ListSessions(ctx context.Context) (*dto.SessionsResponse, error)
+	RevokeSession(ctx context.Context, sessionID string) error
+	RevokeOtherSessions(ctx context.Context) error
+	GetJWKS(ctx context.Context) *dto.JWKSResponse
+	BumpNotBefore(ctx context.Context, userGUID string) (*dto.NotBeforeResponse, error)
+	Introspect(ctx context.Context, request *models.TokenRequest) (*dto.IntrospectionResponse, error)
+	Revoke(ctx context.Context, request *models.TokenRequest) error
 }
 
 type authService struct {
+	cfg       config.Session
+	oauthCfg  config.OAuth
 	repo      repository.Repository
 	cryptor   cryptor.Cryptor
 	tokenizer tokenizer.Tokenizer
+	notifier  notifier.Dispatcher
 	logger    *slog.Logger
 }
 
-func New(repo repository.Repository, cryptor cryptor.Cryptor, tok tokenizer.Tokenizer, logger *slog.Logger) Service {
+func New(cfg config.Session, oauthCfg config.OAuth, repo repository.Repository, cryptor cryptor.Cryptor,
+	tok tokenizer.Tokenizer, notifier notifier.Dispatcher, logger *slog.Logger) Service {
 	return &authService{
+		cfg:       cfg,
+		oauthCfg:  oauthCfg,
 		repo:      repo,
 		cryptor:   cryptor,
 		tokenizer: tok,
+		notifier:  notifier,
 		logger:    logger,
 	}
 }
@@ -48,48 +68,33 @@ func (s *authService) Login(ctx context.Context, login *models.Login) (*dto.Logi
 		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrUserGUIDInvalid, err)
 	}
 
-	if len(login.IP) < 7 || len(login.IP) > 15 {
-		return nil, nil, fmt.Errorf("%w: %s", serverrors.ErrIpAddressInvalid, login.IP)
-	}
-
-	session, err := s.repo.GetSession(ctx, &queries.GetSessionQuery{
-		UserGUID:  login.UserGUID,
-		UserAgent: login.UserAgent,
-	})
-	if err != nil {
-		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrGetSession, err)
-	}
-
-	if session != nil {
-		if time.Now().After(session.ExpiresAt) {
-			err := s.repo.DeleteSession(ctx, session.ID)
-			if err != nil {
-				return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrDeleteSession, err)
-			}
-		}
-		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrSessionAlreadyExists, err)
+	if !login.IP.IsValid() {
+		return nil, nil, serverrors.ErrIpAddressInvalid
 	}
 
+	sessionID := uuid.NewString()
 	tokenPairID := uuid.NewString()
-	accessToken, err := s.tokenizer.GenerateAccessTokenJWT(login.UserGUID, tokenPairID)
+	accessToken, err := s.tokenizer.GenerateAccessTokenJWT(login.UserGUID, sessionID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrAccessTokenGeneration, err)
 	}
 
-	refreshCookie := s.tokenizer.GenerateRefreshTokenCookie()
+	refreshCookie := s.tokenizer.GenerateRefreshTokenCookie(sessionID)
 
-	refreshTokenHash, err := s.cryptor.EncryptKeyword(refreshCookie.Value)
+	refreshTokenHash, err := s.cryptor.EncryptKeyword(ctx, refreshCookie.Value)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrHashingProcess, err)
+		return nil, nil, cryptorError(err, serverrors.ErrHashingProcess)
 	}
 
 	err = s.repo.CreateSession(ctx, &queries.CreateSessionQuery{
-		UserGUID:     login.UserGUID,
-		RefreshToken: refreshTokenHash,
-		UserAgent:    login.UserAgent,
-		IP:           login.IP,
-		PairID:       tokenPairID,
-		ExpiresAt:    refreshCookie.Expires,
+		ID:              sessionID,
+		UserGUID:        login.UserGUID,
+		RefreshToken:    refreshTokenHash,
+		UserAgent:       login.UserAgent,
+		IP:              login.IP,
+		PairID:          tokenPairID,
+		ExpiresAt:       refreshCookie.Expires,
+		MaxUserSessions: s.cfg.MaxPerUser,
 	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrCreateSession, err)
@@ -108,28 +113,17 @@ func (s *authService) GetCurrentUser(ctx context.Context) (*dto.UserResponse, er
 }
 
 func (s *authService) Refresh(ctx context.Context, refresh *models.Refresh) (*dto.RefreshResponse, *http.Cookie, error) {
-	if len(refresh.IP) < 7 || len(refresh.IP) > 15 {
-		return nil, nil, fmt.Errorf("%w: %s", serverrors.ErrIpAddressInvalid, refresh.IP)
-	}
-
-	tokenClaims, err := s.tokenizer.VerifyAccessTokenJWT(refresh.AccessToken, true)
-	if err != nil {
-		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrOldAccessTokenInvalid, err)
+	if !refresh.IP.IsValid() {
+		return nil, nil, serverrors.ErrIpAddressInvalid
 	}
 
-	userGUID, err := tokenClaims.GetSubject()
+	sessionID, err := tokenizer.ParseRefreshToken(refresh.RefreshToken)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrOldAccessTokenInvalid, err)
-	}
-
-	accessPairID, ok := tokenClaims[tokenizer.PairClaimsKey].(string)
-	if !ok {
-		return nil, nil, fmt.Errorf("%w: %s", serverrors.ErrOldAccessTokenInvalid, "no access pair id")
+		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrRefreshTokenInvalid, err)
 	}
 
 	session, err := s.repo.GetSession(ctx, &queries.GetSessionQuery{
-		UserGUID:  userGUID,
-		UserAgent: refresh.UserAgent,
+		SessionID: sessionID,
 	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrGetSession, err)
@@ -139,7 +133,12 @@ func (s *authService) Refresh(ctx context.Context, refresh *models.Refresh) (*dt
 		return nil, nil, serverrors.ErrNoRefreshSession
 	}
 
-	if time.Now().After(session.ExpiresAt) || refresh.UserAgent != session.UserAgent {
+	notBefore, err := s.repo.GetNotBefore(ctx, session.UserGUID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrGetNotBefore, err)
+	}
+
+	if time.Now().After(session.ExpiresAt) || revocation.IssuedBefore(refreshIssuedAt(session), notBefore) {
 		err := s.repo.DeleteSession(ctx, session.ID)
 		if err != nil {
 			return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrDeleteSession, err)
@@ -147,65 +146,280 @@ func (s *authService) Refresh(ctx context.Context, refresh *models.Refresh) (*dt
 		return nil, nil, serverrors.ErrNoRefreshSession
 	}
 
-	// TODO: IP checking.
-
-	if accessPairID != session.PairID {
-		return nil, nil, serverrors.ErrTokenPairInvalid
+	err = s.cryptor.CompareHashAndKeyword(ctx, session.RefreshToken, refresh.RefreshToken)
+	if err != nil {
+		if errors.Is(err, cryptor.ErrHashMismatch) {
+			return s.refreshRotatedPair(ctx, session, refresh)
+		}
+		return nil, nil, cryptorError(err, serverrors.ErrRefreshTokenInvalid)
 	}
 
-	err = s.cryptor.CompareHashAndKeyword(session.RefreshToken, refresh.RefreshToken)
+	err = s.checkIPChange(ctx, session, refresh.IP)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrRefreshTokenInvalid, err)
+		return nil, nil, err
 	}
 
 	newTokenPairID := uuid.NewString()
-	newAccessToken, err := s.tokenizer.GenerateAccessTokenJWT(session.UserGUID, newTokenPairID)
+	newAccessToken, err := s.tokenizer.GenerateAccessTokenJWT(session.UserGUID, session.ID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrAccessTokenGeneration, err)
 	}
 
-	newRefreshCookie := s.tokenizer.GenerateRefreshTokenCookie()
+	newRefreshCookie := s.tokenizer.GenerateRefreshTokenCookie(session.ID)
 
-	newRefreshTokenHash, err := s.cryptor.EncryptKeyword(newRefreshCookie.Value)
+	newRefreshTokenHash, err := s.cryptor.EncryptKeyword(ctx, newRefreshCookie.Value)
 	if err != nil {
-		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrHashingProcess, err)
+		return nil, nil, cryptorError(err, serverrors.ErrHashingProcess)
 	}
 
-	err = s.repo.RenewSession(ctx, session.ID, &queries.CreateSessionQuery{
-		UserGUID:     session.UserGUID,
+	var sealedSuccessor []byte
+	if s.cfg.RefreshGracePeriod > 0 {
+		sealedSuccessor, err = sealSuccessor(&successor{
+			AccessToken:  *newAccessToken,
+			RefreshToken: newRefreshCookie.Value,
+			ExpiresAt:    newRefreshCookie.Expires,
+		}, refresh.RefreshToken)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrRenewSession, err)
+		}
+	}
+
+	err = s.repo.RenewSession(ctx, &queries.RenewSessionQuery{
+		SessionID:    session.ID,
 		RefreshToken: newRefreshTokenHash,
-		UserAgent:    refresh.UserAgent,
 		IP:           refresh.IP,
 		PairID:       newTokenPairID,
 		ExpiresAt:    newRefreshCookie.Expires,
+
+		ConsumedPairID:      session.PairID,
+		ConsumedTokenDigest: tokenizer.RefreshTokenDigest(refresh.RefreshToken),
+		ConsumedSuccessor:   sealedSuccessor,
 	})
 	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			// A concurrent refresh of the same pair won the swap.
+			return s.refreshRotatedPair(ctx, session, refresh)
+		}
 		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrRenewSession, err)
 	}
 
 	return dtomap.MapToRefreshResponse(*newAccessToken), newRefreshCookie, nil
 }
 
-func (s *authService) Logout(ctx context.Context, userAgent string) error {
+func (s *authService) Logout(ctx context.Context) error {
 	currUserGUID, ok := ctx.Value(middleware.UserGUIDKey).(string)
 	if !ok {
 		return serverrors.ErrGUIDExtraction
 	}
 
+	currSessionID, ok := ctx.Value(middleware.SessionIDKey).(string)
+	if !ok {
+		return serverrors.ErrSessionIDExtraction
+	}
+
 	session, err := s.repo.GetSession(ctx, &queries.GetSessionQuery{
-		UserGUID:  currUserGUID,
-		UserAgent: userAgent,
+		SessionID: currSessionID,
 	})
 	if err != nil {
 		return fmt.Errorf("%w: %w", serverrors.ErrGetSession, err)
 	}
 
-	if session != nil {
+	if session != nil && session.UserGUID == currUserGUID {
+		err := s.repo.DeleteSession(ctx, session.ID)
+		if err != nil {
+			return fmt.Errorf("%w: %w", serverrors.ErrDeleteSession, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *authService) ListSessions(ctx context.Context) (*dto.SessionsResponse, error) {
+	currUserGUID, ok := ctx.Value(middleware.UserGUIDKey).(string)
+	if !ok {
+		return nil, serverrors.ErrGUIDExtraction
+	}
+
+	currSessionID, ok := ctx.Value(middleware.SessionIDKey).(string)
+	if !ok {
+		return nil, serverrors.ErrSessionIDExtraction
+	}
+
+	sessions, err := s.repo.ListUserSessions(ctx, currUserGUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serverrors.ErrListSessions, err)
+	}
+
+	return dtomap.MapToSessionsResponse(sessions, currSessionID), nil
+}
+
+func (s *authService) RevokeSession(ctx context.Context, sessionID string) error {
+	currUserGUID, ok := ctx.Value(middleware.UserGUIDKey).(string)
+	if !ok {
+		return serverrors.ErrGUIDExtraction
+	}
+
+	err := uuid.Validate(sessionID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", serverrors.ErrSessionIDInvalid, err)
+	}
+
+	err = s.repo.DeleteUserSession(ctx, &queries.DeleteUserSessionQuery{
+		UserGUID:  currUserGUID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return serverrors.ErrSessionNotFound
+		}
+		return fmt.Errorf("%w: %w", serverrors.ErrDeleteSession, err)
+	}
+
+	return nil
+}
+
+func (s *authService) RevokeOtherSessions(ctx context.Context) error {
+	currUserGUID, ok := ctx.Value(middleware.UserGUIDKey).(string)
+	if !ok {
+		return serverrors.ErrGUIDExtraction
+	}
+
+	currSessionID, ok := ctx.Value(middleware.SessionIDKey).(string)
+	if !ok {
+		return serverrors.ErrSessionIDExtraction
+	}
+
+	err := s.repo.DeleteOtherUserSessions(ctx, &queries.DeleteOtherUserSessionsQuery{
+		UserGUID:      currUserGUID,
+		KeepSessionID: currSessionID,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", serverrors.ErrDeleteSession, err)
+	}
+
+	return nil
+}
+
+func (s *authService) GetJWKS(ctx context.Context) *dto.JWKSResponse {
+	return dtomap.MapToJWKSResponse(s.tokenizer.PublicKeys())
+}
+
+// BumpNotBefore invalidates every token of the user issued until now, or of all users for an empty GUID.
+func (s *authService) BumpNotBefore(ctx context.Context, userGUID string) (*dto.NotBeforeResponse, error) {
+	if userGUID != "" {
+		err := uuid.Validate(userGUID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", serverrors.ErrUserGUIDInvalid, err)
+		}
+	}
+
+	notBefore, err := s.repo.BumpNotBefore(ctx, &queries.BumpNotBeforeQuery{
+		UserGUID:  userGUID,
+		NotBefore: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", serverrors.ErrBumpNotBefore, err)
+	}
+
+	s.logger.Warn("not-before epoch bumped", slog.String("user_guid", userGUID), slog.Time("not_before", notBefore))
+
+	return dtomap.MapToNotBeforeResponse(userGUID, notBefore), nil
+}
+
+// refreshRotatedPair handles a refresh token that is not the session's current one.
+// Within the grace period after its rotation the token gets the same successor back,
+// so concurrent refreshes all succeed. Later it means the refresh token was replayed,
+// and the whole token family is revoked.
+func (s *authService) refreshRotatedPair(ctx context.Context, session *models.Session,
+	refresh *models.Refresh) (*dto.RefreshResponse, *http.Cookie, error) {
+	consumed, err := s.repo.GetConsumedRefreshToken(ctx, tokenizer.RefreshTokenDigest(refresh.RefreshToken))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrGetConsumedToken, err)
+	}
+
+	if consumed == nil || consumed.SessionID != session.ID {
+		return nil, nil, serverrors.ErrRefreshTokenInvalid
+	}
+
+	if len(consumed.Successor) > 0 && time.Since(consumed.ConsumedAt) <= s.cfg.RefreshGracePeriod {
+		pair, err := openSuccessor(consumed.Successor, refresh.RefreshToken)
+		if err == nil {
+			err = s.checkIPChange(ctx, session, refresh.IP)
+			if err != nil {
+				return nil, nil, err
+			}
+			return dtomap.MapToRefreshResponse(pair.AccessToken), s.tokenizer.RefreshTokenCookie(pair.RefreshToken, pair.ExpiresAt), nil
+		}
+	}
+
+	err = s.repo.DeleteSession(ctx, session.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", serverrors.ErrDeleteSession, err)
+	}
+
+	s.emitSecurityEvent(models.SecurityEventRefreshTokenReused, session, refresh.IP)
+
+	return nil, nil, serverrors.ErrRefreshTokenReused
+}
+
+// checkIPChange applies the IP change policy to a refresh from the given IP:
+// a changed IP raises a security event and, under the reject policy, deletes the session.
+func (s *authService) checkIPChange(ctx context.Context, session *models.Session, ip netip.Addr) error {
+	if ip == session.IP {
+		return nil
+	}
+
+	s.emitSecurityEvent(models.SecurityEventIPChanged, session, ip)
+
+	if s.cfg.IPChangePolicy == config.IPChangePolicyReject {
 		err := s.repo.DeleteSession(ctx, session.ID)
 		if err != nil {
 			return fmt.Errorf("%w: %w", serverrors.ErrDeleteSession, err)
 		}
+		return fmt.Errorf("%w: %s -> %s", serverrors.ErrSessionIPChanged, session.IP, ip)
 	}
 
 	return nil
 }
+
+// refreshIssuedAt is when the current refresh token of the session was issued,
+// at its creation or last refresh.
+func refreshIssuedAt(session *models.Session) time.Time {
+	if !session.RefreshedAt.IsZero() {
+		return session.RefreshedAt
+	}
+	return session.CreatedAt
+}
+
+// emitSecurityEvent logs the event and queues it for the notifier without blocking the request.
+func (s *authService) emitSecurityEvent(eventType string, session *models.Session, ip netip.Addr) {
+	s.logger.Warn("security event",
+		slog.String("type", eventType),
+		slog.String("session_id", session.ID),
+		slog.String("previous_ip", session.IP.String()),
+		slog.String("ip", ip.String()))
+
+	event := &models.SecurityEvent{
+		Type:       eventType,
+		UserGUID:   session.UserGUID,
+		SessionID:  session.ID,
+		UserAgent:  session.UserAgent,
+		PreviousIP: session.IP,
+		IP:         ip,
+		OccurredAt: time.Now(),
+	}
+
+	s.notifier.Dispatch(event)
+}
+
+// cryptorError wraps a cryptor failure into the given service error,
+// unless it comes from the hashing pool being saturated or the request going away.
+func cryptorError(err, serviceErr error) error {
+	if errors.Is(err, cryptor.ErrOverloaded) || errors.Is(err, cryptor.ErrPoolClosed) {
+		return fmt.Errorf("%w: %w", serverrors.ErrHashingOverloaded, err)
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", serverrors.ErrHashingProcess, err)
+	}
+	return fmt.Errorf("%w: %w", serviceErr, err)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var errSuccessorSealed = errors.New("successor token pair cannot be opened")
+
+// successor is the token pair issued when a refresh token is consumed.
+// It is stored sealed with a key derived from the consumed refresh token,
+// so only a holder of that token can get the same pair back during the grace period.
+type successor struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func sealSuccessor(pair *successor, consumedRefreshToken string) ([]byte, error) {
+	aead, err := successorAEAD(consumedRefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := json.Marshal(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func openSuccessor(sealed []byte, consumedRefreshToken string) (*successor, error) {
+	aead, err := successorAEAD(consumedRefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errSuccessorSealed
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errSuccessorSealed
+	}
+
+	var pair successor
+	if err := json.Unmarshal(plaintext, &pair); err != nil {
+		return nil, err
+	}
+
+	return &pair, nil
+}
+
+func successorAEAD(consumedRefreshToken string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte("refresh-successor:" + consumedRefreshToken))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,73 @@
+package sweeper
+
+import (
+	"auth-service/internal/metrics"
+	"auth-service/internal/repository"
+	"auth-service/internal/types/queries"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Sweeper deletes expired sessions, which are otherwise only dropped when their user logs in again.
+type Sweeper interface {
+	// Run sweeps on every interval until ctx is done.
+	Run(ctx context.Context)
+}
+
+type sweeper struct {
+	repo      repository.Repository
+	interval  time.Duration
+	batchSize int
+	metrics   *metrics.Metrics
+	logger    *slog.Logger
+}
+
+func New(repo repository.Repository, interval time.Duration, batchSize int, m *metrics.Metrics, logger *slog.Logger) Sweeper {
+	return &sweeper{
+		repo:      repo,
+		interval:  interval,
+		batchSize: batchSize,
+		metrics:   m,
+		logger:    logger,
+	}
+}
+
+func (s *sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.sweep(ctx)
+	}
+}
+
+func (s *sweeper) sweep(ctx context.Context) {
+	deleted, locked, err := s.repo.DeleteExpiredSessions(ctx, &queries.DeleteExpiredSessionsQuery{
+		ExpiredBefore: time.Now(),
+		BatchSize:     s.batchSize,
+	})
+	if err != nil {
+		s.metrics.ObserveSweep(metrics.OutcomeError, deleted)
+		if ctx.Err() == nil {
+			s.logger.Error("expired session sweep failed", slog.Int64("deleted", deleted), slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	if !locked {
+		s.metrics.ObserveSweep(metrics.OutcomeSkipped, 0)
+		return
+	}
+
+	s.metrics.ObserveSweep(metrics.OutcomeOK, deleted)
+	if deleted > 0 {
+		s.logger.Info("expired sessions swept", slog.Int64("deleted", deleted))
+	}
+}
@@ -0,0 +1,245 @@
+package tokenizer
+
+import (
+	"auth-service/internal/types/models"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms.
+const (
+	AlgorithmHS512 = "HS512"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+const (
+	minRSAKeyBits   = 2048
+	hmacSecretBytes = 64
+)
+
+var (
+	ErrAlgorithmUnsupported = errors.New("tokenizer: unsupported signing algorithm")
+	ErrKeyInvalid           = errors.New("tokenizer: invalid signing key")
+)
+
+// Key is a named signing key. For asymmetric algorithms only its public half is used for verification.
+type Key struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+// LoadKey builds the signing key from the HMAC secret for HS512 or from a PEM private key file otherwise.
+// Without an explicit key ID one is derived from the key material.
+func LoadKey(alg, kid, secret, keyFile string) (*Key, error) {
+	if alg == AlgorithmHS512 {
+		return NewKey(alg, kid, []byte(secret))
+	}
+
+	material, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKeyInvalid, err)
+	}
+
+	return NewKey(alg, kid, material)
+}
+
+// NewKey builds the signing key from an HMAC secret for HS512 or a PEM encoded private key otherwise.
+func NewKey(alg, kid string, material []byte) (*Key, error) {
+	key := &Key{id: kid}
+
+	switch alg {
+	case AlgorithmHS512:
+		if len(material) == 0 {
+			return nil, fmt.Errorf("%w: empty secret", ErrKeyInvalid)
+		}
+		key.method = jwt.SigningMethodHS512
+		key.signKey, key.verifyKey = material, material
+	case AlgorithmRS256:
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(material)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrKeyInvalid, err)
+		}
+		if private.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("%w: rsa key shorter than %d bits", ErrKeyInvalid, minRSAKeyBits)
+		}
+		key.method = jwt.SigningMethodRS256
+		key.signKey, key.verifyKey = private, &private.PublicKey
+	case AlgorithmES256:
+		private, err := jwt.ParseECPrivateKeyFromPEM(material)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrKeyInvalid, err)
+		}
+		if private.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: ES256 requires a P-256 key", ErrKeyInvalid)
+		}
+		key.method = jwt.SigningMethodES256
+		key.signKey, key.verifyKey = private, &private.PublicKey
+	case AlgorithmEdDSA:
+		private, err := jwt.ParseEdPrivateKeyFromPEM(material)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrKeyInvalid, err)
+		}
+		edPrivate, ok := private.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: EdDSA requires an Ed25519 key", ErrKeyInvalid)
+		}
+		key.method = jwt.SigningMethodEdDSA
+		key.signKey, key.verifyKey = edPrivate, edPrivate.Public()
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrAlgorithmUnsupported, alg)
+	}
+
+	if key.id == "" {
+		key.id = key.thumbprint()
+	}
+
+	return key, nil
+}
+
+func (k *Key) ID() string {
+	return k.id
+}
+
+func (k *Key) Algorithm() string {
+	return k.method.Alg()
+}
+
+// Public returns the verification key, or nil for symmetric keys which must never be published.
+func (k *Key) Public() crypto.PublicKey {
+	if k.method == jwt.SigningMethodHS512 {
+		return nil
+	}
+	return k.verifyKey
+}
+
+// JWK returns the public part of the key in JSON Web Key form.
+// The second result is false for symmetric keys.
+func (k *Key) JWK() (models.JWK, bool) {
+	jwk := models.JWK{
+		KeyID:     k.id,
+		Algorithm: k.Algorithm(),
+		Use:       "sig",
+	}
+
+	switch public := k.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encodeSegment(public.N.Bytes())
+		jwk.E = encodeSegment(big.NewInt(int64(public.E)).Bytes())
+	case *ecdsa.PublicKey:
+		ecdhPublic, err := public.ECDH()
+		if err != nil {
+			return models.JWK{}, false
+		}
+		// Uncompressed point: 0x04 || X || Y.
+		point := ecdhPublic.Bytes()
+		size := (len(point) - 1) / 2
+		jwk.KeyType = "EC"
+		jwk.Curve = public.Curve.Params().Name
+		jwk.X = encodeSegment(point[1 : 1+size])
+		jwk.Y = encodeSegment(point[1+size:])
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = encodeSegment(public)
+	default:
+		return models.JWK{}, false
+	}
+
+	return jwk, true
+}
+
+// thumbprint derives a key ID: the RFC 7638 thumbprint for asymmetric keys
+// and a digest of the secret for symmetric ones.
+func (k *Key) thumbprint() string {
+	jwk, ok := k.JWK()
+	if !ok {
+		digest := sha256.Sum256(append([]byte("kid:"), k.signKey.([]byte)...))
+		return encodeSegment(digest[:12])
+	}
+
+	var members any
+	switch jwk.KeyType {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Curve, jwk.KeyType, jwk.X, jwk.Y}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	}
+
+	canonical, _ := json.Marshal(members)
+	digest := sha256.Sum256(canonical)
+
+	return encodeSegment(digest[:])
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// GenerateKeyMaterial creates fresh key material for the algorithm in the form accepted by NewKey:
+// a PKCS #8 PEM private key, or a random secret for HS512.
+func GenerateKeyMaterial(alg string) ([]byte, error) {
+	var private any
+	var err error
+
+	switch alg {
+	case AlgorithmHS512:
+		secret := make([]byte, hmacSecretBytes)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		return []byte(encodeSegment(secret)), nil
+	case AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, minRSAKeyBits)
+	case AlgorithmES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrAlgorithmUnsupported, alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
@@ -1,9 +1,15 @@
 package tokenizer
 
 import (
+	"auth-service/internal/config"
+	"auth-service/internal/types/models"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,47 +17,76 @@ import (
 )
 
 const (
-	RefreshCookieName = "refresh_session"
-	PairClaimsKey     = "pair"
+	SessionClaimsKey = "sid"
+)
+
+const (
+	refreshSecretBytes    = 32
+	refreshTokenSeparator = "."
 )
 
 var (
+	ErrRefreshTokenMalformed = errors.New("refresh token malformed")
+
 	ErrTokenInvalid = errors.New("access token invalid")
 	ErrTokenExpired = errors.New("access token expired")
+	ErrNoSigningKey = errors.New("no active signing key")
 )
 
+// KeyRing provides the key tokens are signed with and the keys they are verified against.
+type KeyRing interface {
+	SigningKey() *Key
+	// VerificationKey looks a key up by ID; tokens without a kid header are looked up by an empty ID.
+	VerificationKey(kid string) (*Key, bool)
+	VerificationKeys() []*Key
+}
+
 type Tokenizer interface {
-	GenerateAccessTokenJWT(userID, pairID string) (*string, error)
+	GenerateAccessTokenJWT(userID, sessionID string) (*string, error)
 	VerifyAccessTokenJWT(tokenString string, skipExpired bool) (jwt.MapClaims, error)
-	GenerateRefreshTokenCookie() *http.Cookie
+	GenerateRefreshTokenCookie(sessionID string) *http.Cookie
+	RefreshTokenCookie(refreshToken string, expires time.Time) *http.Cookie
+	ExpiredRefreshTokenCookie() *http.Cookie
+	RefreshCookieName() string
+	RefreshCookiePaths() []string
+	PublicKeys() []models.JWK
+	Issuer() string
 }
 
 type tokenizer struct {
 	tokenIssuer        string
-	jwtSecret          []byte
+	keys               KeyRing
 	accessTokenExpire  time.Duration
 	refreshTokenExpire time.Duration
+	cookie             config.Cookie
 }
 
-func New(iss, jwtKey string, accessExpire, refreshExpire time.Duration) Tokenizer {
+func New(iss string, keys KeyRing, accessExpire, refreshExpire time.Duration, cookie config.Cookie) Tokenizer {
 	return &tokenizer{
 		tokenIssuer:        iss,
-		jwtSecret:          []byte(jwtKey),
+		keys:               keys,
 		accessTokenExpire:  accessExpire,
 		refreshTokenExpire: refreshExpire,
+		cookie:             cookie,
 	}
 }
 
-func (t *tokenizer) GenerateAccessTokenJWT(userGUID, pairID string) (*string, error) {
-	claims := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.MapClaims{
-		"sub":         userGUID,
-		PairClaimsKey: pairID,
-		"iss":         t.tokenIssuer,
-		"exp":         time.Now().Add(t.accessTokenExpire).Unix(),
-		"iat":         time.Now().Unix(),
+func (t *tokenizer) GenerateAccessTokenJWT(userGUID, sessionID string) (*string, error) {
+	key := t.keys.SigningKey()
+	if key == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	claims := jwt.NewWithClaims(key.method, jwt.MapClaims{
+		"sub":            userGUID,
+		SessionClaimsKey: sessionID,
+		"iss":            t.tokenIssuer,
+		"exp":            time.Now().Add(t.accessTokenExpire).Unix(),
+		"iat":            time.Now().Unix(),
 	})
+	claims.Header["kid"] = key.id
 
-	accessToken, err := claims.SignedString(t.jwtSecret)
+	accessToken, err := claims.SignedString(key.signKey)
 	if err != nil {
 		return nil, err
 	}
@@ -61,10 +96,12 @@ func (t *tokenizer) GenerateAccessTokenJWT(userGUID, pairID string) (*string, er
 
 func (t *tokenizer) VerifyAccessTokenJWT(tokenString string, skipExpired bool) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if token.Method.Alg() != jwt.SigningMethodHS512.Alg() {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := t.keys.VerificationKey(kid)
+		if !ok || token.Method.Alg() != key.Algorithm() {
 			return nil, ErrTokenInvalid
 		}
-		return t.jwtSecret, nil
+		return key.verifyKey, nil
 	})
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) && !skipExpired {
@@ -80,12 +117,103 @@ func (t *tokenizer) VerifyAccessTokenJWT(tokenString string, skipExpired bool) (
 	return nil, ErrTokenInvalid
 }
 
-func (t *tokenizer) GenerateRefreshTokenCookie() *http.Cookie {
-	refreshToken := base64.StdEncoding.EncodeToString([]byte(uuid.NewString()))
+// GenerateRefreshTokenCookie issues an opaque refresh token "<session id>.<secret>"
+// carrying a 256-bit secret from crypto/rand.
+func (t *tokenizer) GenerateRefreshTokenCookie(sessionID string) *http.Cookie {
+	secret := make([]byte, refreshSecretBytes)
+	rand.Read(secret)
+
+	refreshToken := sessionID + refreshTokenSeparator + base64.RawURLEncoding.EncodeToString(secret)
+	return t.RefreshTokenCookie(refreshToken, time.Now().Add(t.refreshTokenExpire))
+}
+
+// RefreshTokenCookie builds the refresh cookie by the cookie policy, on the first of its paths.
+func (t *tokenizer) RefreshTokenCookie(refreshToken string, expires time.Time) *http.Cookie {
+	if capped := time.Now().Add(t.cookie.MaxAge); t.cookie.MaxAge > 0 && capped.Before(expires) {
+		expires = capped
+	}
+
+	cookie := t.policyCookie()
+	cookie.Value = refreshToken
+	cookie.Expires = expires
+	cookie.MaxAge = int(time.Until(expires).Seconds())
+	if cookie.MaxAge <= 0 {
+		cookie.MaxAge = -1
+	}
+
+	return cookie
+}
+
+// ExpiredRefreshTokenCookie makes the client drop the refresh cookie, matching the attributes it was set with.
+func (t *tokenizer) ExpiredRefreshTokenCookie() *http.Cookie {
+	cookie := t.policyCookie()
+	cookie.Expires = time.Unix(0, 0)
+	cookie.MaxAge = -1
+
+	return cookie
+}
+
+func (t *tokenizer) RefreshCookieName() string {
+	return t.cookie.CookieName()
+}
+
+func (t *tokenizer) RefreshCookiePaths() []string {
+	return t.cookie.Paths
+}
+
+func (t *tokenizer) policyCookie() *http.Cookie {
+	sameSite := http.SameSiteStrictMode
+	switch t.cookie.SameSite {
+	case config.SameSiteLax:
+		sameSite = http.SameSiteLaxMode
+	case config.SameSiteNone:
+		sameSite = http.SameSiteNoneMode
+	}
+
 	return &http.Cookie{
-		Name:    RefreshCookieName,
-		Value:   refreshToken,
-		Expires: time.Now().Add(t.refreshTokenExpire),
-		Path:    "/api/auth/refresh",
+		Name:     t.cookie.CookieName(),
+		Domain:   t.cookie.Domain,
+		Path:     t.cookie.Paths[0],
+		SameSite: sameSite,
+		Secure:   !t.cookie.Insecure,
+		HttpOnly: !t.cookie.ScriptAccess,
+	}
+}
+
+func (t *tokenizer) Issuer() string {
+	return t.tokenIssuer
+}
+
+// PublicKeys returns the verification keys which can be published, symmetric keys are never exposed.
+func (t *tokenizer) PublicKeys() []models.JWK {
+	var keys []models.JWK
+	for _, key := range t.keys.VerificationKeys() {
+		if jwk, ok := key.JWK(); ok {
+			keys = append(keys, jwk)
+		}
+	}
+
+	return keys
+}
+
+// ParseRefreshToken extracts the session ID a refresh token belongs to.
+func ParseRefreshToken(refreshToken string) (string, error) {
+	sessionID, secret, ok := strings.Cut(refreshToken, refreshTokenSeparator)
+	if !ok || uuid.Validate(sessionID) != nil {
+		return "", ErrRefreshTokenMalformed
 	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(secret)
+	if err != nil || len(decoded) != refreshSecretBytes {
+		return "", ErrRefreshTokenMalformed
+	}
+
+	return sessionID, nil
+}
+
+// RefreshTokenDigest is a deterministic, unkeyed digest of a refresh token for lookups.
+// Its 256-bit secret makes the digest safe to store.
+func RefreshTokenDigest(refreshToken string) string {
+	digest := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(digest[:])
 }
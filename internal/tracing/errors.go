@@ -0,0 +1,9 @@
+package tracing
+
+import "errors"
+
+// Tracing errors.
+var (
+	ErrUnknownExporter = errors.New("tracing: unknown exporter")
+	ErrExporterInit    = errors.New("tracing: exporter init failed")
+)
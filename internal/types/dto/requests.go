@@ -3,3 +3,8 @@ package dto
 type LoginRequest struct {
 	UserGUID string
 }
+
+type TokenRequest struct {
+	Token         string
+	TokenTypeHint string
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type LoginResponse struct {
 	AccessToken string `json:"access_token"`
 }
@@ -12,7 +14,86 @@ type UserResponse struct {
 	UserGUID string `json:"user_guid"`
 }
 
+type SessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+type SessionResponse struct {
+	ID          string     `json:"id"`
+	UserAgent   string     `json:"user_agent"`
+	IP          string     `json:"ip"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RefreshedAt *time.Time `json:"refreshed_at,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	Current     bool       `json:"current"`
+}
+
 type ErrorResponse struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
+	// RetryAfter is sent as the Retry-After header in seconds when positive.
+	RetryAfter int `json:"-"`
+}
+
+type SecurityEventPayload struct {
+	Type       string    `json:"type"`
+	UserGUID   string    `json:"user_guid"`
+	SessionID  string    `json:"session_id"`
+	UserAgent  string    `json:"user_agent"`
+	PreviousIP string    `json:"previous_ip"`
+	IP         string    `json:"ip"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
+
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+type NotBeforeResponse struct {
+	// UserGUID is empty for the global epoch.
+	UserGUID  string    `json:"user_guid,omitempty"`
+	Global    bool      `json:"global"`
+	NotBefore time.Time `json:"not_before"`
+}
+
+// IntrospectionResponse is the RFC 7662 introspection response, only "active" is set for inactive tokens.
+type IntrospectionResponse struct {
+	Active           bool   `json:"active"`
+	TokenType        string `json:"token_type,omitempty"`
+	Subject          string `json:"sub,omitempty"`
+	ExpiresAt        int64  `json:"exp,omitempty"`
+	IssuedAt         int64  `json:"iat,omitempty"`
+	Issuer           string `json:"iss,omitempty"`
+	ClientID         string `json:"client_id,omitempty"`
+	Scope            string `json:"scope,omitempty"`
+	SessionID        string `json:"sid,omitempty"`
+	SessionCreatedAt int64  `json:"session_created_at,omitempty"`
+	SessionExpiresAt int64  `json:"session_expires_at,omitempty"`
+}
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+type ReadinessResponse struct {
+	Status string                   `json:"status"`
+	Checks map[string]CheckResponse `json:"checks"`
+}
+
+type CheckResponse struct {
+	Status string `json:"status"`
+	// Error explains a failed check.
+	Error string `json:"error,omitempty"`
 }
@@ -1,18 +1,20 @@
 package models
 
-import "time"
+import (
+	"net/netip"
+	"time"
+)
 
 type Login struct {
 	UserGUID  string
 	UserAgent string
-	IP        string
+	IP        netip.Addr
 }
 
 type Refresh struct {
-	AccessToken  string
 	RefreshToken string
 	UserAgent    string
-	IP           string
+	IP           netip.Addr
 }
 
 type User struct {
@@ -24,8 +26,87 @@ type Session struct {
 	UserGUID     string
 	RefreshToken string
 	UserAgent    string
-	IP           string
+	IP           netip.Addr
 	PairID       string
 	ExpiresAt    time.Time
 	CreatedAt    time.Time
+	RefreshedAt  time.Time
+}
+
+// ConsumedRefreshToken is a refresh token rotated out of its family, the session it belonged to.
+type ConsumedRefreshToken struct {
+	TokenDigest string
+	SessionID   string
+	ConsumedAt  time.Time
+	// Successor is the sealed token pair issued in exchange, empty when no grace period applies.
+	Successor []byte
+}
+
+// Security event types.
+const (
+	SecurityEventIPChanged          = "session_ip_changed"
+	SecurityEventRefreshTokenReused = "refresh_token_reused"
+)
+
+type SecurityEvent struct {
+	Type       string
+	UserGUID   string
+	SessionID  string
+	UserAgent  string
+	PreviousIP netip.Addr
+	IP         netip.Addr
+	OccurredAt time.Time
+}
+
+type JWK struct {
+	KeyType   string
+	KeyID     string
+	Algorithm string
+	Use       string
+	N         string
+	E         string
+	Curve     string
+	X         string
+	Y         string
+}
+
+type SigningKey struct {
+	ID          string
+	Algorithm   string
+	PrivateKey  string
+	Active      bool
+	CreatedAt   time.Time
+	ActivatedAt time.Time
+	VerifyUntil time.Time
+}
+
+// Token type hints of the OAuth introspection and revocation endpoints.
+const (
+	TokenTypeAccess  = "access_token"
+	TokenTypeRefresh = "refresh_token"
+)
+
+// TokenRequest is a token presented by an OAuth client, with an optional hint of its type.
+type TokenRequest struct {
+	Token         string
+	TokenTypeHint string
+}
+
+// TokenInfo describes an active token.
+type TokenInfo struct {
+	Type      string
+	Subject   string
+	ExpiresAt time.Time
+	IssuedAt  time.Time
+	Issuer    string
+	ClientID  string
+	Scope     string
+	Session   *Session
+}
+
+// SchemaVersion is the applied migration version against the one the service was built for.
+type SchemaVersion struct {
+	Current  uint
+	Expected uint
+	Dirty    bool
 }
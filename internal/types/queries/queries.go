@@ -1,17 +1,68 @@
 package queries
 
-import "time"
+import (
+	"net/netip"
+	"time"
+)
 
 type GetSessionQuery struct {
-	UserGUID  string
-	UserAgent string
+	SessionID string
 }
 
 type CreateSessionQuery struct {
+	ID           string
 	UserGUID     string
 	RefreshToken string
 	UserAgent    string
-	IP           string
+	IP           netip.Addr
+	PairID       string
+	ExpiresAt    time.Time
+	// MaxUserSessions caps the sessions kept per user, evicting the oldest ones. Zero means no limit.
+	MaxUserSessions int
+}
+
+type DeleteUserSessionQuery struct {
+	UserGUID  string
+	SessionID string
+}
+
+type DeleteOtherUserSessionsQuery struct {
+	UserGUID      string
+	KeepSessionID string
+}
+
+type RenewSessionQuery struct {
+	SessionID    string
+	RefreshToken string
+	IP           netip.Addr
 	PairID       string
 	ExpiresAt    time.Time
+	// ConsumedPairID is the pair being rotated out: the renewal only applies while it is still current.
+	// The consumed token is kept by its digest with the sealed successor for reuse detection.
+	ConsumedPairID      string
+	ConsumedTokenDigest string
+	ConsumedSuccessor   []byte
+}
+
+type CreateSigningKeyQuery struct {
+	ID         string
+	Algorithm  string
+	PrivateKey string
+}
+
+type PromoteSigningKeyQuery struct {
+	ID string
+	// DemotedVerifyUntil bounds how long the previously active key keeps verifying tokens.
+	DemotedVerifyUntil time.Time
+}
+
+type BumpNotBeforeQuery struct {
+	// UserGUID selects the user's epoch, empty selects the global one.
+	UserGUID  string
+	NotBefore time.Time
+}
+
+type DeleteExpiredSessionsQuery struct {
+	ExpiredBefore time.Time
+	BatchSize     int
 }
@@ -1,43 +1,110 @@
 package cryptor
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const tracerName = "auth-service/cryptor"
 
 type Cryptor interface {
-	EncryptKeyword(keyword string) (string, error)
-	CompareHashAndKeyword(hash, keyword string) error
+	EncryptKeyword(ctx context.Context, keyword string) (string, error)
+	CompareHashAndKeyword(ctx context.Context, hash, keyword string) error
+	// Stats reports the load of the hashing worker pool.
+	Stats() PoolStats
+	// Close stops accepting work and waits for queued hashing jobs to finish, at most until ctx is done.
+	Close(ctx context.Context) error
 }
 
 type bcryptor struct {
 	pool *workerPool
 }
 
-func New(asyncHashingLimit int) Cryptor {
+func New(asyncHashingLimit, queueSize int, queueWait time.Duration) Cryptor {
 	return &bcryptor{
-		pool: NewWorkerPool(asyncHashingLimit),
+		pool: NewWorkerPool(asyncHashingLimit, queueSize, queueWait),
 	}
 }
 
-func (c *bcryptor) EncryptKeyword(keyword string) (string, error) {
-	resChan := make(chan string, 1)
+func (c *bcryptor) EncryptKeyword(ctx context.Context, keyword string) (string, error) {
+	var hash []byte
+	err := c.run(ctx, "cryptor.encrypt", func() error {
+		var err error
+		hash, err = bcrypt.GenerateFromPassword(prehash(keyword), bcrypt.MinCost)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+func (c *bcryptor) CompareHashAndKeyword(ctx context.Context, hash, keyword string) error {
+	return c.run(ctx, "cryptor.compare", func() error {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), prehash(keyword))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return ErrHashMismatch
+		}
+		return err
+	})
+}
+
+func (c *bcryptor) Stats() PoolStats {
+	return c.pool.Stats()
+}
+
+func (c *bcryptor) Close(ctx context.Context) error {
+	return c.pool.Close(ctx)
+}
+
+// prehash reduces the keyword to an encoded SHA-256 digest, as bcrypt rejects input over 72 bytes.
+func prehash(keyword string) []byte {
+	digest := sha256.Sum256([]byte(keyword))
+	return []byte(base64.RawStdEncoding.EncodeToString(digest[:]))
+}
+
+// run executes the job on the pool and waits for it unless ctx is done first.
+// Jobs whose caller has gone away by the time a worker picks them up are skipped.
+// The job span covers the time queued for a worker, recorded separately as queue wait.
+func (c *bcryptor) run(ctx context.Context, spanName string, job func() error) (err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, spanName)
+	defer func() {
+		if err != nil && err != ErrHashMismatch {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	queuedAt := time.Now()
 	errChan := make(chan error, 1)
 
-	c.pool.Add(func() {
-		hash, err := bcrypt.GenerateFromPassword([]byte(keyword), bcrypt.MinCost)
-		if err != nil {
+	err = c.pool.Add(ctx, func() {
+		span.AddEvent("dequeued")
+		span.SetAttributes(attribute.Float64("cryptor.queue_wait_seconds", time.Since(queuedAt).Seconds()))
+
+		if err := ctx.Err(); err != nil {
 			errChan <- err
 			return
 		}
-		resChan <- string(hash)
+		errChan <- job()
 	})
+	if err != nil {
+		return err
+	}
 
 	select {
-	case res := <-resChan:
-		return res, nil
 	case err := <-errChan:
-		return "", err
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
-
-func (c *bcryptor) CompareHashAndKeyword(hash, keyword string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(keyword))
-}
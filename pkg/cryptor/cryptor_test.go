@@ -0,0 +1,71 @@
+package cryptor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBcryptorLongKeyword(t *testing.T) {
+	c := New(1, 1, time.Second)
+	defer c.Close(context.Background())
+
+	keyword := strings.Repeat("k", 80)
+	hash, err := c.EncryptKeyword(context.Background(), keyword)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+
+	if err := c.CompareHashAndKeyword(context.Background(), hash, keyword); err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+
+	err = c.CompareHashAndKeyword(context.Background(), hash, keyword[:79]+"x")
+	if !errors.Is(err, ErrHashMismatch) {
+		t.Fatalf("compare other keyword: got %v, want %v", err, ErrHashMismatch)
+	}
+}
+
+func TestPoolCloseWakesWaitingProducers(t *testing.T) {
+	pool := NewWorkerPool(1, 1, time.Minute)
+
+	release := make(chan struct{})
+	if err := pool.Add(context.Background(), func() { <-release }); err != nil {
+		t.Fatalf("add blocking task: %v", err)
+	}
+	// Wait for the worker to take the blocking task, so the next one fills the queue.
+	for pool.Stats().Busy == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := pool.Add(context.Background(), func() {}); err != nil {
+		t.Fatalf("fill queue: %v", err)
+	}
+
+	added := make(chan error, 1)
+	go func() {
+		added <- pool.Add(context.Background(), func() {})
+	}()
+	// Let the producer block on the full queue.
+	time.Sleep(10 * time.Millisecond)
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- pool.Close(context.Background())
+	}()
+
+	select {
+	case err := <-added:
+		if !errors.Is(err, ErrPoolClosed) {
+			t.Fatalf("waiting add: got %v, want %v", err, ErrPoolClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiting add not woken by close")
+	}
+
+	close(release)
+	if err := <-closed; err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+package cryptor
+
+import "errors"
+
+// Cryptor errors.
+var (
+	ErrOverloaded   = errors.New("cryptor: hashing pool overloaded")
+	ErrPoolClosed   = errors.New("cryptor: hashing pool closed")
+	ErrHashMismatch = errors.New("cryptor: hash does not match keyword")
+)
@@ -1,14 +1,45 @@
 package cryptor
 
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PoolStats is a snapshot of the worker pool load.
+type PoolStats struct {
+	Workers       int
+	Busy          int
+	Queued        int
+	QueueCapacity int
+}
+
+// workerPool runs tasks on a fixed number of workers behind a bounded queue.
+// Callers wait for a queue slot at most queueWait, so a saturated pool sheds load instead of piling up.
 type workerPool struct {
-	tasks chan func()
+	tasks     chan func()
+	queueWait time.Duration
+	workers   int
+	busy      atomic.Int64
+
+	// done is closed first on Close, waking producers waiting for a slot,
+	// so the write lock guarding the close of tasks is not held up by their queue wait.
+	done      chan struct{}
+	closeOnce sync.Once
+	mu        sync.RWMutex
+	wg        sync.WaitGroup
 }
 
-func NewWorkerPool(maxWorkers int) *workerPool {
+func NewWorkerPool(maxWorkers, queueSize int, queueWait time.Duration) *workerPool {
 	pool := &workerPool{
-		tasks: make(chan func(), maxWorkers),
+		tasks:     make(chan func(), queueSize),
+		queueWait: queueWait,
+		workers:   maxWorkers,
+		done:      make(chan struct{}),
 	}
 
+	pool.wg.Add(maxWorkers)
 	for i := 0; i < maxWorkers; i++ {
 		go pool.worker()
 	}
@@ -16,11 +47,76 @@ func NewWorkerPool(maxWorkers int) *workerPool {
 }
 
 func (p *workerPool) worker() {
+	defer p.wg.Done()
 	for task := range p.tasks {
+		p.busy.Add(1)
 		task()
+		p.busy.Add(-1)
+	}
+}
+
+// Add queues the task, failing with ErrOverloaded when no slot frees up within the queue wait.
+func (p *workerPool) Add(ctx context.Context, task func()) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	select {
+	case <-p.done:
+		return ErrPoolClosed
+	default:
+	}
+
+	select {
+	case p.tasks <- task:
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(p.queueWait)
+	defer timer.Stop()
+
+	select {
+	case p.tasks <- task:
+		return nil
+	case <-timer.C:
+		return ErrOverloaded
+	case <-p.done:
+		return ErrPoolClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns the current pool load.
+func (p *workerPool) Stats() PoolStats {
+	return PoolStats{
+		Workers:       p.workers,
+		Busy:          int(p.busy.Load()),
+		Queued:        len(p.tasks),
+		QueueCapacity: cap(p.tasks),
 	}
 }
 
-func (p *workerPool) Add(task func()) {
-	p.tasks <- task
+// Close stops accepting tasks and waits until the queued ones are done or ctx is done.
+func (p *workerPool) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		close(p.done)
+
+		p.mu.Lock()
+		close(p.tasks)
+		p.mu.Unlock()
+	})
+
+	drained := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }